@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kluzzebass/reflag/cmdline"
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// auditReport is the result of reflag audit-history. Each invocation of a
+// source tool is counted once, by its worst outcome: unknown (the target's
+// --help doesn't list a flag reflag would emit), dropped (a lossy flag),
+// approximate, or clean.
+type auditReport struct {
+	Files       []string          `json:"files"`
+	Commands    int               `json:"commands"` // history entries read
+	Invocations int               `json:"invocations"`
+	Clean       int               `json:"clean"`
+	Approximate int               `json:"approximate"`
+	Dropped     int               `json:"dropped"`
+	Unknown     int               `json:"unknown"`
+	Translators []auditTranslator `json:"translators"`
+	Patterns    []auditPattern    `json:"patterns"`  // most frequent first
+	Unchecked   []string          `json:"unchecked"` // targets whose --help couldn't be read
+}
+
+// auditTranslator holds the counts for one translator
+type auditTranslator struct {
+	Name        string `json:"name"`
+	Invocations int    `json:"invocations"`
+	Clean       int    `json:"clean"`
+	Approximate int    `json:"approximate"`
+	Dropped     int    `json:"dropped"`
+	Unknown     int    `json:"unknown"`
+}
+
+// auditPattern is a flag that doesn't translate, with how often it was seen
+type auditPattern struct {
+	Pattern string `json:"pattern"` // e.g. "find -perm" or "rg --frobnicate"
+	Kind    string `json:"kind"`    // "dropped" or "unknown"
+	Reason  string `json:"reason"`
+	Count   int    `json:"count"`
+}
+
+// invocation is a source tool command found in a history entry
+type invocation struct {
+	tool string
+	args []string
+}
+
+// defaultHistoryFiles returns the usual history locations for bash, zsh
+// and fish, with $HISTFILE first
+func defaultHistoryFiles() []string {
+	var files []string
+	if f := os.Getenv("HISTFILE"); f != "" {
+		files = append(files, f)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return files
+	}
+	zdot := cmp.Or(os.Getenv("ZDOTDIR"), home)
+	data := cmp.Or(os.Getenv("XDG_DATA_HOME"), filepath.Join(home, ".local", "share"))
+	for _, f := range []string{
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(zdot, ".zsh_history"),
+		filepath.Join(data, "fish", "fish_history"),
+	} {
+		if !slices.Contains(files, f) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// readHistory reads the entries of a bash, zsh or fish history file
+func readHistory(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("- cmd: ")) || bytes.Contains(data, []byte("\n- cmd: ")) {
+		return parseFishHistory(bytes.NewReader(data))
+	}
+	zsh := strings.Contains(filepath.Base(path), "zsh") || zshExtendedRe.Match(data)
+	return parseLineHistory(bytes.NewReader(data), zsh)
+}
+
+// zshExtendedRe matches the ": start:elapsed;" prefix of zsh's extended
+// history format
+var zshExtendedRe = regexp.MustCompile(`(?m)^: \d+:\d+;`)
+
+// bashTimestampRe matches the lines bash writes before each entry when
+// HISTTIMEFORMAT is set
+var bashTimestampRe = regexp.MustCompile(`^#\d+$`)
+
+// parseLineHistory parses bash history and zsh history in either the plain
+// or the extended format. zsh ends the lines of a multi-line entry with a
+// backslash and stores some bytes "metafied", which is undone when zsh is set.
+func parseLineHistory(r io.Reader, zsh bool) ([]string, error) {
+	var entries []string
+	var pending []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if zsh {
+			line = unmetafy(line)
+		}
+		if pending == nil {
+			if bashTimestampRe.MatchString(line) {
+				continue
+			}
+			if loc := zshExtendedRe.FindStringIndex(line); loc != nil {
+				line = line[loc[1]:]
+			}
+		}
+		if zsh && strings.HasSuffix(line, `\`) {
+			pending = append(pending, strings.TrimSuffix(line, `\`))
+			continue
+		}
+		entries = append(entries, strings.Join(append(pending, line), "\n"))
+		pending = nil
+	}
+	if pending != nil {
+		entries = append(entries, strings.Join(pending, "\n"))
+	}
+	return entries, sc.Err()
+}
+
+// unmetafy undoes zsh's history encoding, which writes bytes it treats
+// specially as 0x83 followed by the byte xor 32
+func unmetafy(s string) string {
+	if strings.IndexByte(s, 0x83) < 0 {
+		return s
+	}
+	b := []byte(s)
+	out := b[:0]
+	for i := 0; i < len(b); i++ {
+		if b[i] == 0x83 && i+1 < len(b) {
+			i++
+			out = append(out, b[i]^32)
+			continue
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+// parseFishHistory parses fish_history, a YAML-like list of entries. Each
+// starts with a "- cmd: " line, followed by indented when: and paths: keys.
+// Backslashes and newlines in commands are escaped as \\ and \n.
+func parseFishHistory(r io.Reader) ([]string, error) {
+	var entries []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		if cmd, ok := strings.CutPrefix(sc.Text(), "- cmd: "); ok {
+			entries = append(entries, unescapeFish(cmd))
+		}
+	}
+	return entries, sc.Err()
+}
+
+// unescapeFish undoes the escaping of fish_history commands
+func unescapeFish(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case '\\':
+				b.WriteByte('\\')
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// findInvocations returns the simple commands in a history entry run by
+// one of tools. Commands spelled with quotes or a backslash are skipped, as
+// reflag wouldn't translate them, and arguments end at the first operator.
+// Entries that can't be split are skipped.
+func findInvocations(entry string, tools map[string]bool) []invocation {
+	words, err := cmdline.Split(entry)
+	if err != nil {
+		return nil
+	}
+	var found []invocation
+	start := true
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if w.Op {
+			start = isControlOp(w.Value)
+			continue
+		}
+		if !start || assignmentRe.MatchString(w.Text) {
+			continue
+		}
+		start = false
+		if w.Quoted || w.Expands || !tools[w.Value] {
+			continue
+		}
+		inv := invocation{tool: w.Value}
+		for i+1 < len(words) && !words[i+1].Op {
+			i++
+			inv.args = append(inv.args, words[i].Value)
+		}
+		found = append(found, inv)
+	}
+	return found
+}
+
+// isControlOp reports whether op starts a new command
+func isControlOp(op string) bool {
+	switch op {
+	case ";", "&", "&&", "||", "|", "|&", "(", ")", ";;", "\n":
+		return true
+	}
+	return false
+}
+
+// translatorsBySource maps each source tool to its translator, the first by
+// name where several translate the same tool
+func translatorsBySource() map[string]translator.Translator {
+	names := translator.List()
+	slices.Sort(names)
+	bySource := make(map[string]translator.Translator)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		if _, ok := bySource[t.SourceTool()]; !ok {
+			bySource[t.SourceTool()] = t
+		}
+	}
+	return bySource
+}
+
+// audit runs the invocations of registered source tools in entries through
+// their translators. lookupFlags returns the flags a target's --help lists;
+// targets it fails for are reported as unchecked. top limits the patterns
+// reported.
+func audit(entries []string, mode string, lookupFlags func(tool string) (helpFlags, error), top int) *auditReport {
+	bySource := translatorsBySource()
+	tools := make(map[string]bool)
+	for source := range bySource {
+		tools[source] = true
+	}
+
+	report := &auditReport{Commands: len(entries), Files: []string{}, Unchecked: []string{}}
+	perTranslator := make(map[string]*auditTranslator)
+	patterns := make(map[string]*auditPattern)
+	targetFlags := make(map[string]helpFlags)
+	addPattern := func(pattern, kind, reason string) {
+		p, ok := patterns[pattern]
+		if !ok {
+			p = &auditPattern{Pattern: pattern, Kind: kind, Reason: reason}
+			patterns[pattern] = p
+		}
+		p.Count++
+	}
+
+	for _, entry := range entries {
+		for _, inv := range findInvocations(entry, tools) {
+			t := bySource[inv.tool]
+			target := t.TargetTool()
+			flags, checked := targetFlags[target]
+			if !checked {
+				var err error
+				if flags, err = lookupFlags(target); err != nil {
+					report.Unchecked = append(report.Unchecked, target)
+				}
+				targetFlags[target] = flags
+			}
+
+			grade, mappings := translator.Grade(t, inv.args, mode)
+			for _, m := range mappings {
+				if m.Fidelity == translator.Lossy {
+					addPattern(inv.tool+" "+m.Flag, "dropped", m.Note)
+				}
+			}
+			var unknown []unknownFlag
+			args := t.Translate(inv.args, mode)
+			if flags != nil {
+				unknown = unknownFlags(flags, nil, args)
+			}
+			for _, u := range unknown {
+				for _, flag := range u.flags {
+					addPattern(target+" "+flag, "unknown", fmt.Sprintf("%s --help does not list %s", target, flag))
+				}
+			}
+
+			c := perTranslator[t.Name()]
+			if c == nil {
+				c = &auditTranslator{Name: t.Name()}
+				perTranslator[t.Name()] = c
+			}
+			c.Invocations++
+			report.Invocations++
+			switch {
+			case len(unknown) > 0:
+				c.Unknown++
+				report.Unknown++
+			case grade == translator.Lossy:
+				c.Dropped++
+				report.Dropped++
+			case grade == translator.Approximate:
+				c.Approximate++
+				report.Approximate++
+			default:
+				c.Clean++
+				report.Clean++
+			}
+		}
+	}
+
+	report.Translators = make([]auditTranslator, 0, len(perTranslator))
+	for _, c := range perTranslator {
+		report.Translators = append(report.Translators, *c)
+	}
+	slices.SortFunc(report.Translators, func(a, b auditTranslator) int { return strings.Compare(a.Name, b.Name) })
+
+	report.Patterns = make([]auditPattern, 0, len(patterns))
+	for _, p := range patterns {
+		report.Patterns = append(report.Patterns, *p)
+	}
+	slices.SortFunc(report.Patterns, func(a, b auditPattern) int {
+		return cmp.Or(b.Count-a.Count, strings.Compare(a.Pattern, b.Pattern))
+	})
+	if top >= 0 && len(report.Patterns) > top {
+		report.Patterns = report.Patterns[:top]
+	}
+	slices.Sort(report.Unchecked)
+	return report
+}
+
+// printAuditTable prints report for people
+func printAuditTable(w io.Writer, report *auditReport) {
+	fmt.Fprintf(w, "Read %d commands from %s\n\n", report.Commands, strings.Join(report.Files, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRANSLATOR\tINVOCATIONS\tCLEAN\tAPPROXIMATE\tDROPPED\tUNKNOWN")
+	for _, c := range report.Translators {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\n", c.Name, c.Invocations, c.Clean, c.Approximate, c.Dropped, c.Unknown)
+	}
+	fmt.Fprintf(tw, "total\t%d\t%d\t%d\t%d\t%d\n", report.Invocations, report.Clean, report.Approximate, report.Dropped, report.Unknown)
+	tw.Flush()
+
+	if len(report.Patterns) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Most frequent untranslatable patterns:")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "COUNT\tPATTERN\tKIND\tREASON")
+		for _, p := range report.Patterns {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", p.Count, p.Pattern, p.Kind, p.Reason)
+		}
+		tw.Flush()
+	}
+
+	if len(report.Unchecked) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Not checked for unknown flags (no readable --help): %s\n", strings.Join(report.Unchecked, ", "))
+	}
+}
+
+// runAuditHistory implements audit-history [--top=N] [FILE...], printing
+// the report in g.format (table or json). Without files the usual bash, zsh and fish
+// history files are read, skipping those that don't exist.
+func runAuditHistory(g globalOptions, args []string) {
+	top := 10
+	var files []string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--top="):
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "--top="))
+			if err != nil || n < 0 {
+				fmt.Fprintf(os.Stderr, "error: bad --top value %q\n", arg)
+				os.Exit(exitError)
+			}
+			top = n
+		case strings.HasPrefix(arg, "--"):
+			fmt.Fprintf(os.Stderr, "error: unknown audit-history option %q\n", arg)
+			os.Exit(exitError)
+		default:
+			files = append(files, arg)
+		}
+	}
+
+	explicit := len(files) > 0
+	if !explicit {
+		files = defaultHistoryFiles()
+	}
+	var entries []string
+	var read []string
+	for _, f := range files {
+		e, err := readHistory(f)
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitError)
+		}
+		entries = append(entries, e...)
+		read = append(read, f)
+	}
+	if len(read) == 0 {
+		fmt.Fprintln(os.Stderr, "error: no history files found; name them on the command line")
+		os.Exit(exitError)
+	}
+
+	report := audit(entries, g.mode, toolFlags, top)
+	report.Files = read
+	if g.format == "json" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+	printAuditTable(os.Stdout, report)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadHistory(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{
+			"bash_history",
+			[]string{
+				"ls -la",
+				"grep -rn TODO src",
+				"find . -name '*.o' -delete",
+				"cd /tmp && ls -lt | head",
+				"FOO=1 grep -s foo bar",
+				`\ls -l`,
+				"git status",
+			},
+		},
+		{
+			"zsh_history",
+			[]string{
+				"ls -la",
+				"for f in *.go; do\ngrep -l main $f\ndone",
+				"echo café",
+				"ps aux | grep -i zsh",
+			},
+		},
+		{
+			"fish_history",
+			[]string{
+				"ls -la",
+				"find . -perm 644",
+				"echo one\ntwo | grep -v \"a\\\\b\"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := readHistory(filepath.Join("testdata", "history", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("readHistory(%s) =\n%q\nwant\n%q", tt.file, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindInvocations(t *testing.T) {
+	tools := map[string]bool{"ls": true, "grep": true, "find": true}
+	tests := []struct {
+		entry    string
+		expected []string // tool and args joined by spaces
+	}{
+		{"ls -la", []string{"ls -la"}},
+		{"cd /tmp && ls -lt | head", []string{"ls -lt"}},
+		{"FOO=1 grep -s foo bar > out", []string{"grep -s foo bar"}},
+		{"for f in *.go; do\ngrep -l main $f\ndone", []string{"grep -l main $f"}},
+		{`\ls -l; "grep" x`, nil},
+		{"echo ls grep", nil},
+		{"grep 'unbalanced", nil},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, inv := range findInvocations(tt.entry, tools) {
+			got = append(got, strings.Join(append([]string{inv.tool}, inv.args...), " "))
+		}
+		if !slices.Equal(got, tt.expected) {
+			t.Errorf("findInvocations(%q) = %q, want %q", tt.entry, got, tt.expected)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	rg := parseHelpFlags(readHelpFixture(t, "rg-14.1.1.txt"))
+	lookup := func(tool string) (helpFlags, error) {
+		if tool == "rg" {
+			return rg, nil
+		}
+		return nil, errors.New("not installed")
+	}
+	entries := []string{
+		"grep -i foo",
+		"grep -i bar | grep -v baz",
+		"grep -s foo",
+		"grep --frobnicate foo",
+		"find . -atime -1",
+		"find . -perm 644",
+		"find . -perm 600 -delete",
+		"git log",
+	}
+
+	report := audit(entries, "gnu", lookup, 2)
+	if report.Commands != 8 || report.Invocations != 8 {
+		t.Errorf("commands, invocations = %d, %d; want 8, 8", report.Commands, report.Invocations)
+	}
+	if report.Clean != 3 || report.Approximate != 1 || report.Dropped != 3 || report.Unknown != 1 {
+		t.Errorf("clean/approximate/dropped/unknown = %d/%d/%d/%d, want 3/1/3/1",
+			report.Clean, report.Approximate, report.Dropped, report.Unknown)
+	}
+	if len(report.Translators) != 2 || report.Translators[0].Name != "find2fd" || report.Translators[1].Name != "grep2rg" {
+		t.Errorf("translators = %+v", report.Translators)
+	}
+	if len(report.Patterns) != 2 || report.Patterns[0].Pattern != "find -perm" || report.Patterns[0].Count != 2 {
+		t.Errorf("patterns = %+v, want find -perm first and two in all", report.Patterns)
+	}
+	if !slices.Equal(report.Unchecked, []string{"fd"}) {
+		t.Errorf("unchecked = %q, want [fd]", report.Unchecked)
+	}
+
+	var b bytes.Buffer
+	printAuditTable(&b, report)
+	for _, want := range []string{"grep2rg     5", "total       8", "2      find -perm", "(no readable --help): fd"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("table missing %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// globalOptions are accepted by every command, anywhere before the source
+// tool. --mode and --config may also directly follow a command's source
+// tool (or source and target): no source tool has flags by those names,
+// while --format and -v would take ls's and grep's.
+type globalOptions struct {
+	mode    string
+	config  string
+	format  string // "" when not given; each command picks its default
+	verbose bool
+}
+
+// option describes a command-line option, for parsing and generated help
+type option struct {
+	name  string // e.g. --validate
+	short string // e.g. -y, or ""
+	value string // placeholder for the value: "" for a switch, "[=X]" if optional
+	help  string
+}
+
+// takesArg reports whether the option's value may be the next argument
+func (o option) takesArg() bool {
+	return o.value != "" && !strings.HasPrefix(o.value, "[")
+}
+
+// usage returns the option as shown in help, e.g. -y, --side-by-side
+func (o option) usage() string {
+	s := o.name
+	switch {
+	case strings.HasPrefix(o.value, "["):
+		s += o.value
+	case o.value != "":
+		s += "=" + o.value
+	}
+	if o.short != "" {
+		s = o.short + ", " + s
+	}
+	return s
+}
+
+// globalOptionList describes globalOptions
+var globalOptionList = []option{
+	{name: "--mode", value: "MODE", help: "Source tool dialect: gnu, bsd or busybox; detected if not given"},
+	{name: "--config", value: "FILE", help: "Read settings from FILE instead of $REFLAG_CONFIG or ~/.config/reflag/config"},
+	{name: "--format", value: "FORMAT", help: "Output format; the values depend on the command"},
+	{name: "--verbose", short: "-v", help: "Report what reflag decided on standard error"},
+}
+
+// command is a reflag subcommand. Its options are checked and normalized
+// to --name=value form, then passed on with the arguments to run, which
+// interprets them.
+type command struct {
+	name     string
+	aliases  []string // older spellings, e.g. --init
+	args     string   // synopsis of the positional arguments
+	summary  string
+	details  string // more help text, if the summary isn't enough
+	options  []option
+	formats  []string // values accepted for --format; the first is the default
+	operands int      // arguments after which the rest belongs to the source tool; 0 if none do
+	run      func(g globalOptions, args []string)
+}
+
+// translateOptions are shared by translate and explain
+var translateOptions = []option{
+	{name: "--validate", value: "POLICY", help: "Check emitted flags against the target's --help: off (default), warn or drop; overrides $REFLAG_VALIDATE and the config file"},
+	{name: "--min-fidelity", value: "LEVEL", help: "Emit the original command when the translation grades below LEVEL: exact, approximate or lossy (default, accepts all); overrides $REFLAG_MIN_FIDELITY and the config file"},
+	{name: "--pipe", value: "POLICY", help: "What to do when output is not a terminal: always translate (default), tty-only (run the source tool) or tty-or-compatible (ask the target for parseable output, else run the source tool); overrides $REFLAG_PIPE and the config file"},
+}
+
+// initOptionList describes the options parseInitOptions accepts
+var initOptionList = []option{
+	{name: "--widget", value: "[=enter]", help: "Emit a key binding (Ctrl-X t) that rewrites the command line in place instead of wrapper functions; =enter also rewrites every line when Enter is pressed"},
+	{name: "--all", help: "Include translators whose target tool is not on PATH"},
+	{name: "--wrap", value: "[=LIST]", help: "Also wrap prefix commands (sudo, watch, xargs, env, time, nice) and translate the command they run"},
+	{name: "--portable", help: "Emit plain sh functions that translate simple flags without reflag, for pasting into a remote shell's startup file; other commands run untranslated"},
+}
+
+// commands lists reflag's subcommands in the order help shows them
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			name:     "translate",
+			args:     "<source> <target> [flags...]",
+			summary:  "Print the translated command (the default command)",
+			options:  append(slices.Clone(translateOptions), option{name: "--explain", help: "Same as reflag explain"}),
+			formats:  []string{"shell", "json"},
+			operands: 2,
+			run:      func(g globalOptions, args []string) { runTranslate(g, args, false) },
+		},
+		{
+			name:     "explain",
+			aliases:  []string{"--explain"},
+			args:     "<source> <target> [flags...]",
+			summary:  "Describe a translation: each flag's fidelity and any routing rule",
+			options:  translateOptions,
+			operands: 2,
+			run:      func(g globalOptions, args []string) { runTranslate(g, args, true) },
+		},
+		{
+			name:    "init",
+			aliases: []string{"--init"},
+			args:    "[SHELL] [selection...]",
+			summary: "Print shell code that wraps the source tools",
+			details: "SHELL is bash (the default), zsh, sh, ksh, fish, nu, elvish or xonsh. The selection picks translators; see reflag --help.",
+			options: initOptionList,
+			run:     func(g globalOptions, args []string) { runInit(args, printInit) },
+		},
+		{
+			name:    "deinit",
+			aliases: []string{"--deinit"},
+			args:    "[SHELL] [selection...]",
+			summary: "Print shell code that removes what init set up",
+			details: "SHELL is bash (the default), zsh, sh, ksh or fish. Pass the options and selection given to init.",
+			options: initOptionList,
+			run:     func(g globalOptions, args []string) { runInit(args, printDeinit) },
+		},
+		{
+			name:    "list",
+			aliases: []string{"--list", "-l"},
+			args:    "[selection...]",
+			summary: "List the available translators",
+			formats: []string{"table", "json"},
+			run:     runList,
+		},
+		{
+			name:    "doctor",
+			args:    "[selection...]",
+			summary: "Check the config file, shell setup and installed target tools",
+			run:     runDoctor,
+		},
+		{
+			name:    "version",
+			aliases: []string{"--version", "-V"},
+			summary: "Print the version",
+			run:     func(g globalOptions, args []string) { printVersion("reflag") },
+		},
+		{
+			name:    "install",
+			args:    "[selection...]",
+			summary: "Add a block loading reflag to the shell's startup file",
+			options: []option{
+				{name: "--shell", value: "SHELL", help: "bash, zsh or fish (defaults to the basename of $SHELL)"},
+				{name: "--dry-run", help: "Print the change to the rc file as a diff without writing it"},
+			},
+			run: func(g globalOptions, args []string) { runInstall("install", args) },
+		},
+		{
+			name:    "uninstall",
+			summary: "Remove the block install added",
+			options: []option{
+				{name: "--shell", value: "SHELL", help: "bash, zsh or fish (defaults to the basename of $SHELL)"},
+				{name: "--dry-run", help: "Print the change to the rc file as a diff without writing it"},
+			},
+			run: func(g globalOptions, args []string) { runInstall("uninstall", args) },
+		},
+		{
+			name:    "off",
+			args:    "[translator]",
+			summary: "Switch translation off in the current shell, or one translator",
+			options: []option{{name: "--shell", value: "SHELL", help: "Print code for SHELL (sh or fish); the reflag shell function passes it"}},
+			run:     func(g globalOptions, args []string) { runToggle("off", args) },
+		},
+		{
+			name:    "on",
+			args:    "[translator]",
+			summary: "Switch translation back on",
+			options: []option{{name: "--shell", value: "SHELL", help: "Print code for SHELL (sh or fish); the reflag shell function passes it"}},
+			run:     func(g globalOptions, args []string) { runToggle("on", args) },
+		},
+		{
+			name:    "pause",
+			args:    "DURATION [translator]",
+			summary: "Switch translation off for a while, e.g. 30m",
+			options: []option{{name: "--shell", value: "SHELL", help: "Print code for SHELL (sh or fish); the reflag shell function passes it"}},
+			run:     func(g globalOptions, args []string) { runToggle("pause", args) },
+		},
+		{
+			name:     "compare",
+			args:     "<source> [flags...]",
+			summary:  "Run a command and its translation and diff their output",
+			operands: 1,
+			options: []option{
+				{name: "--timeout", value: "DURATION", help: "Stop each command after DURATION (default 10s)"},
+				{name: "--side-by-side", short: "-y", help: "Show the outputs in two columns"},
+				{name: "--unified", help: "Show a unified diff (the default)"},
+				{name: "--width", value: "N", help: "Width of the side-by-side view (default $COLUMNS or 160)"},
+				{name: "--raw", help: "Compare the output as is, without stripping colors and whitespace"},
+			},
+			run: runCompare,
+		},
+		{
+			name:    "audit-history",
+			args:    "[FILE...]",
+			summary: "Measure how well your shell history translates",
+			options: []option{{name: "--top", value: "N", help: "Show the N most frequent imperfect patterns (default 10)"}},
+			formats: []string{"table", "json"},
+			run:     runAuditHistory,
+		},
+		{
+			name:    "serve",
+			summary: "Answer translations over a unix socket for the zsh wrappers",
+			options: []option{{name: "--socket", value: "PATH", help: "Listen on PATH instead of $REFLAG_SOCKET or the default"}},
+			run:     func(g globalOptions, args []string) { runServe(args) },
+		},
+		{
+			name:    "help",
+			aliases: []string{"--help", "-h"},
+			args:    "[command]",
+			summary: "Show help for reflag or a command",
+			run:     runHelpCommand,
+		},
+	}
+	// Commands for working on reflag itself, before help
+	commands = slices.Insert(commands, len(commands)-1, devCommands...)
+}
+
+// findCommand returns the command called name, or nil
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name || slices.Contains(c.aliases, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// findOption returns the option arg names in opts, or nil. It matches
+// --name, --name=value and the short form.
+func findOption(opts []option, arg string) *option {
+	name, _, _ := strings.Cut(arg, "=")
+	for i, o := range opts {
+		if name == o.name || (o.short != "" && arg == o.short) {
+			return &opts[i]
+		}
+	}
+	return nil
+}
+
+// parseGlobal parses the global option at args[i] into g. It returns how
+// many arguments it used, 0 if args[i] isn't a global option.
+func parseGlobal(args []string, i int, g *globalOptions) (int, error) {
+	o := findOption(globalOptionList, args[i])
+	if o == nil {
+		return 0, nil
+	}
+	if o.value == "" {
+		if strings.Contains(args[i], "=") {
+			return 0, fmt.Errorf("%s takes no value", o.name)
+		}
+		g.verbose = true
+		return 1, nil
+	}
+
+	n := 1
+	value, ok := strings.CutPrefix(args[i], o.name+"=")
+	if !ok {
+		if i+1 >= len(args) {
+			return 0, fmt.Errorf("%s needs a value", o.name)
+		}
+		value, n = args[i+1], 2
+	}
+	switch o.name {
+	case "--mode":
+		g.mode = value
+	case "--config":
+		g.config = value
+	case "--format":
+		g.format = value
+	}
+	return n, nil
+}
+
+// parseCommandLine splits reflag's arguments into global options, the
+// command and the arguments for it. Without a command word the arguments
+// are for translate, which keeps the original reflag <source> <target> form.
+func parseCommandLine(args []string) (g globalOptions, cmd *command, rest []string, help bool, err error) {
+	i := 0
+	for i < len(args) {
+		n, err := parseGlobal(args, i, &g)
+		if err != nil {
+			return g, nil, nil, false, err
+		}
+		if n == 0 {
+			break
+		}
+		i += n
+	}
+	if i == len(args) {
+		return g, findCommand("help"), nil, false, nil
+	}
+
+	cmd = findCommand(args[i])
+	if cmd == nil {
+		cmd = findCommand("translate")
+	} else {
+		i++
+	}
+	rest, help, err = cmd.parse(args[i:], &g)
+	return g, cmd, rest, help, err
+}
+
+// parse checks the command's options, taking out global ones and --help.
+// Other options come back as --name=value, in order with the arguments.
+func (c *command) parse(args []string, g *globalOptions) (rest []string, help bool, err error) {
+	operands := 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if c.operands > 0 && operands == c.operands {
+			// Only unambiguous global options may follow the source tool
+			if o := findOption(globalOptionList, arg); o != nil && (o.name == "--mode" || o.name == "--config") {
+				n, err := parseGlobal(args, i, g)
+				if err != nil {
+					return nil, false, err
+				}
+				i += n - 1
+				continue
+			}
+			return append(rest, args[i:]...), help, nil
+		}
+		if arg == "--" {
+			return append(rest, args[i:]...), help, nil
+		}
+		if (arg == "--help" || arg == "-h") && c.name != "help" {
+			help = true
+			continue
+		}
+		n, err := parseGlobal(args, i, g)
+		if err != nil {
+			return nil, false, err
+		}
+		if n > 0 {
+			i += n - 1
+			continue
+		}
+
+		o := findOption(c.options, arg)
+		switch {
+		case o != nil && o.takesArg() && !strings.Contains(arg, "="):
+			if i+1 >= len(args) {
+				return nil, false, fmt.Errorf("%s needs a value", o.name)
+			}
+			rest = append(rest, o.name+"="+args[i+1])
+			i++
+		case o != nil:
+			rest = append(rest, arg)
+		case strings.HasPrefix(arg, "--"):
+			return nil, false, fmt.Errorf("unknown %s option %q (see reflag %s --help)", c.name, arg, c.name)
+		default:
+			// Single-dash words are arguments: selections use -term
+			rest = append(rest, arg)
+			operands++
+		}
+	}
+	return rest, help, nil
+}
+
+// checkFormat validates g.format for the command and fills in its default
+func (c *command) checkFormat(g *globalOptions) error {
+	if g.format == "" {
+		if len(c.formats) > 0 {
+			g.format = c.formats[0]
+		}
+		return nil
+	}
+	if !slices.Contains(c.formats, g.format) {
+		if len(c.formats) == 0 {
+			return fmt.Errorf("reflag %s has no --format option", c.name)
+		}
+		return fmt.Errorf("unknown format %q (expected %s)", g.format, strings.Join(c.formats, " or "))
+	}
+	return nil
+}
+
+// runCommandLine runs the command args select
+func runCommandLine(args []string) {
+	g, cmd, rest, help, err := parseCommandLine(args)
+	if err == nil && !help {
+		err = cmd.checkFormat(&g)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if help {
+		printCommandHelp(os.Stdout, cmd)
+		return
+	}
+	if g.config != "" {
+		// Set rather than passed around, so commands reflag runs see it too
+		os.Setenv("REFLAG_CONFIG", g.config)
+	}
+	cmd.run(g, rest)
+}
+
+// runHelpCommand implements reflag help [command]
+func runHelpCommand(g globalOptions, args []string) {
+	if len(args) == 0 {
+		printUsage()
+		return
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil || cmd.name == "help" {
+		fmt.Fprintf(os.Stderr, "error: unknown command %q\n", args[0])
+		os.Exit(exitError)
+	}
+	printCommandHelp(os.Stdout, cmd)
+}
+
+// printCommandHelp prints the generated help for cmd
+func printCommandHelp(w io.Writer, cmd *command) {
+	synopsis := "reflag " + cmd.name
+	if len(cmd.options) > 0 {
+		synopsis += " [options]"
+	}
+	if cmd.args != "" {
+		synopsis += " " + cmd.args
+	}
+	fmt.Fprintf(w, "usage: %s\n\n%s\n", synopsis, cmd.summary)
+	if cmd.details != "" {
+		fmt.Fprintf(w, "\n%s\n", wrapText(cmd.details, 0, 80))
+	}
+	if len(cmd.aliases) > 0 {
+		fmt.Fprintf(w, "Also: reflag %s\n", strings.Join(cmd.aliases, ", reflag "))
+	}
+	if len(cmd.options) > 0 {
+		fmt.Fprintln(w, "\nOptions:")
+		writeOptions(w, cmd.options)
+	}
+	fmt.Fprintln(w, "\nGlobal options:")
+	writeOptions(w, globalOptionList)
+	if len(cmd.formats) > 0 {
+		fmt.Fprintf(w, "\nFormats: %s (default %s)\n", strings.Join(cmd.formats, ", "), cmd.formats[0])
+	}
+}
+
+// writeOptions prints an option table, wrapping the help text
+func writeOptions(w io.Writer, opts []option) {
+	const indent = 20
+	for _, o := range opts {
+		line := "  " + o.usage()
+		if len(line) >= indent-1 {
+			fmt.Fprintln(w, line)
+			line = ""
+		}
+		line += strings.Repeat(" ", indent-len(line))
+		fmt.Fprintln(w, line+wrapText(o.help, indent, 80))
+	}
+}
+
+// wrapText wraps s to width columns, indenting lines after the first by
+// indent spaces; the first line is assumed to start at column indent
+func wrapText(s string, indent, width int) string {
+	var b strings.Builder
+	col := indent
+	for i, word := range strings.Fields(s) {
+		switch {
+		case i == 0:
+		case col+1+len(word) > width:
+			b.WriteString("\n" + strings.Repeat(" ", indent))
+			col = indent
+		default:
+			b.WriteByte(' ')
+			col++
+		}
+		b.WriteString(word)
+		col += len(word)
+	}
+	return b.String()
+}
+
+// writeCommands prints the command summary for reflag's usage
+func writeCommands(w io.Writer) {
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", c.name, c.summary)
+	}
+}
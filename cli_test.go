@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		command string
+		g       globalOptions
+		rest    []string
+		help    bool
+	}{
+		{"legacy translate", []string{"ls", "eza", "-l"}, "translate", globalOptions{}, []string{"ls", "eza", "-l"}, false},
+		{"mode first", []string{"--mode=bsd", "ls", "eza", "-l"}, "translate", globalOptions{mode: "bsd"}, []string{"ls", "eza", "-l"}, false},
+		{"mode after target", []string{"ls", "eza", "--mode=bsd", "-l"}, "translate", globalOptions{mode: "bsd"}, []string{"ls", "eza", "-l"}, false},
+		{"mode between tools", []string{"translate", "ls", "--mode", "gnu", "eza", "-l"}, "translate", globalOptions{mode: "gnu"}, []string{"ls", "eza", "-l"}, false},
+		{"source tool's format", []string{"ls", "eza", "--format=long", "-v"}, "translate", globalOptions{}, []string{"ls", "eza", "--format=long", "-v"}, false},
+		{"source tool's mode", []string{"ls", "eza", "-l", "--mode=bsd"}, "translate", globalOptions{}, []string{"ls", "eza", "-l", "--mode=bsd"}, false},
+		{"globals around command", []string{"-v", "translate", "--format", "json", "--validate", "warn", "ls", "eza"}, "translate",
+			globalOptions{format: "json", verbose: true}, []string{"--validate=warn", "ls", "eza"}, false},
+		{"old option form", []string{"--validate=warn", "--explain", "ls", "eza"}, "translate", globalOptions{}, []string{"--validate=warn", "--explain", "ls", "eza"}, false},
+		{"explain alias", []string{"--mode=bsd", "--explain", "ls", "eza", "-l"}, "explain", globalOptions{mode: "bsd"}, []string{"ls", "eza", "-l"}, false},
+		{"init alias", []string{"--init", "bash", "-ls2eza", "--widget", "--config", "/x"}, "init", globalOptions{config: "/x"}, []string{"bash", "-ls2eza", "--widget"}, false},
+		{"list alias", []string{"-l", "pagers"}, "list", globalOptions{}, []string{"pagers"}, false},
+		{"version", []string{"-V"}, "version", globalOptions{}, nil, false},
+		{"compare", []string{"compare", "-y", "--timeout", "2s", "ls", "--mode=gnu", "-v"}, "compare", globalOptions{mode: "gnu"}, []string{"-y", "--timeout=2s", "ls", "-v"}, false},
+		{"audit-history", []string{"audit-history", "--top", "3", "--format=json", "h"}, "audit-history", globalOptions{format: "json"}, []string{"--top=3", "h"}, false},
+		{"command help", []string{"init", "--help"}, "init", globalOptions{}, nil, true},
+		{"translated help", []string{"ls", "eza", "-h"}, "translate", globalOptions{}, []string{"ls", "eza", "-h"}, false},
+		{"no arguments", nil, "help", globalOptions{}, nil, false},
+		{"only globals", []string{"-v"}, "help", globalOptions{verbose: true}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, cmd, rest, help, err := parseCommandLine(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cmd.name != tt.command || g != tt.g || !slices.Equal(rest, tt.rest) || help != tt.help {
+				t.Errorf("parseCommandLine(%q) = %s %+v %q help=%v, want %s %+v %q help=%v",
+					tt.args, cmd.name, g, rest, help, tt.command, tt.g, tt.rest, tt.help)
+			}
+		})
+	}
+}
+
+func TestParseCommandLineErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"--mode"},
+		{"--verbose=1", "ls", "eza"},
+		{"translate", "--bogus", "ls", "eza"},
+		{"list", "--all"},
+		{"compare", "--timeout"},
+	} {
+		if _, _, _, _, err := parseCommandLine(args); err == nil {
+			t.Errorf("parseCommandLine(%q) should fail", args)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		command, format, want string
+		ok                    bool
+	}{
+		{"translate", "", "shell", true},
+		{"translate", "json", "json", true},
+		{"translate", "table", "", false},
+		{"audit-history", "", "table", true},
+		{"list", "", "table", true},
+		{"list", "json", "json", true},
+		{"version", "json", "", false},
+	}
+
+	for _, tt := range tests {
+		g := globalOptions{format: tt.format}
+		err := findCommand(tt.command).checkFormat(&g)
+		if (err == nil) != tt.ok || (tt.ok && g.format != tt.want) {
+			t.Errorf("%s --format=%q: format %q, error %v", tt.command, tt.format, g.format, err)
+		}
+	}
+}
+
+func TestPrintCommandHelp(t *testing.T) {
+	for _, cmd := range commands {
+		var b bytes.Buffer
+		printCommandHelp(&b, cmd)
+		if !strings.HasPrefix(b.String(), "usage: reflag "+cmd.name) || !strings.Contains(b.String(), "-v, --verbose") {
+			t.Errorf("help for %s:\n%s", cmd.name, b.String())
+		}
+		for _, line := range strings.Split(b.String(), "\n") {
+			if len(line) > 80 {
+				t.Errorf("help for %s has a line over 80 columns: %q", cmd.name, line)
+			}
+		}
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	t.Setenv("REFLAG_CONFIG", config)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("PATH", fakeBin(t, "ls", "eza"))
+
+	var b bytes.Buffer
+	if n := doctor(&b, []string{"ls2eza"}, false); n != 0 {
+		t.Errorf("doctor found %d problems:\n%s", n, b.String())
+	}
+	for _, want := range []string{"(not found, using defaults)", "not set up in", "ls2eza ", "active"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("doctor output missing %q:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "grep2rg") {
+		t.Errorf("doctor listed an unselected translator without -v:\n%s", b.String())
+	}
+
+	if err := os.WriteFile(config, []byte("bogus\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b.Reset()
+	if n := doctor(&b, []string{"ls2eza"}, true); n != 1 || !strings.Contains(b.String(), "grep2rg") {
+		t.Errorf("doctor with a broken config = %d problems:\n%s", n, b.String())
+	}
+}
+
+func TestDoctorTargetVersion(t *testing.T) {
+	t.Setenv("REFLAG_CONFIG", os.DevNull)
+	t.Setenv("HOME", t.TempDir())
+	bin := fakeBin(t, "ls")
+	t.Setenv("PATH", bin)
+	eza := filepath.Join(bin, "eza")
+	if err := os.WriteFile(eza, []byte("#!/bin/sh\necho 'eza - A modern replacement for ls'\necho 'v0.10.1 [+git]'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	if n := doctor(&b, []string{"ls2eza", "grep2rg"}, false); n != 1 {
+		t.Errorf("doctor with an old eza found %d problems:\n%s", n, b.String())
+	}
+	for _, want := range []string{"0.10.1", "needs eza 0.18.0", "rg: see https://github.com/BurntSushi/ripgrep"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("doctor output missing %q:\n%s", want, b.String())
+		}
+	}
+}
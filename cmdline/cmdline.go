@@ -0,0 +1,199 @@
+// Package cmdline translates whole shell command lines, for programs that
+// embed reflag rather than running it.
+//
+//	out, err := cmdline.TranslateLine("ls -lt | head", cmdline.Options{})
+//	// out == "eza -l --sort=modified --reverse | head"
+//
+// Translators are registered by importing their packages, as in package main.
+package cmdline
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// Options controls TranslateLine
+type Options struct {
+	// Mode selects the source tool's dialect (e.g. "bsd" or "gnu" for ls2eza)
+	Mode string
+
+	// Shell is the shell the result is quoted for: "sh" (the default),
+	// "bash", "zsh", "ksh" or "fish"
+	Shell string
+
+	// Translators holds selection expressions as understood by
+	// translator.Select: names, globs, tags and +/- modifiers. nil selects
+	// the translators included in --init by default.
+	Translators []string
+
+	// MinFidelity is the lowest translation grade to accept; lines graded
+	// below it give a *FidelityError. The zero value accepts everything.
+	MinFidelity translator.Fidelity
+
+	// Skip, when set, is asked about each command before it is translated;
+	// returning true leaves it to the source tool with ErrSkipped
+	Skip func(tool string, args []string) bool
+}
+
+// ErrNoCommand is returned for lines without a command, such as empty lines
+// or lines with only variable assignments
+var ErrNoCommand = errors.New("no command to translate")
+
+// ErrSkipped is returned for commands Options.Skip leaves untranslated
+var ErrSkipped = errors.New("command skipped")
+
+// UnknownCommandError reports a command none of the selected translators
+// handles. Commands spelled with quotes or a backslash (\ls) are never
+// translated, as that is the usual way of asking for the real tool.
+type UnknownCommandError struct {
+	Command string
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("no translator for command %q", e.Command)
+}
+
+// ExpansionError reports a translated argument built from a parameter or
+// command substitution, which can't be re-quoted without changing its meaning
+type ExpansionError struct {
+	Arg string
+}
+
+func (e *ExpansionError) Error() string {
+	return fmt.Sprintf("translated argument %q comes from a shell expansion", e.Arg)
+}
+
+// FidelityError reports a translation graded below Options.MinFidelity
+type FidelityError struct {
+	Command  string
+	Fidelity translator.Fidelity
+	Mappings []translator.Mapping // the flags that don't translate exactly
+}
+
+func (e *FidelityError) Error() string {
+	return fmt.Sprintf("translation of %q is only %s", e.Command, e.Fidelity)
+}
+
+var assignmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// TranslateLine translates the first command of a shell command line and
+// returns the line with that command replaced. Leading variable assignments
+// and everything after the command's arguments (pipes, redirections,
+// further commands) are kept verbatim. Arguments that come through
+// unchanged keep their original spelling where the target shell reads it
+// the same way, so globs and variables still expand; everything else is
+// quoted for opts.Shell.
+//
+// Errors are a *SyntaxError for lines that can't be split (unbalanced
+// quotes), ErrNoCommand, *UnknownCommandError, ErrSkipped, *FidelityError
+// or *ExpansionError.
+func TranslateLine(line string, opts Options) (string, error) {
+	quote, err := quoter(opts.Shell)
+	if err != nil {
+		return "", err
+	}
+	words, err := Split(line)
+	if err != nil {
+		return "", err
+	}
+
+	// Skip leading variable assignments (FOO=1 ls)
+	i := 0
+	for i < len(words) && !words[i].Op && assignmentRe.MatchString(words[i].Text) {
+		i++
+	}
+	if i == len(words) || words[i].Op {
+		return "", ErrNoCommand
+	}
+	cmd := words[i]
+	if cmd.Quoted || cmd.Expands {
+		return "", &UnknownCommandError{Command: cmd.Text}
+	}
+
+	names, err := translator.Select(translator.Match("default"), opts.Translators)
+	if err != nil {
+		return "", err
+	}
+	var t translator.Translator
+	for _, name := range names {
+		if tr := translator.GetByName(name); tr.SourceTool() == cmd.Value {
+			t = tr
+			break
+		}
+	}
+	if t == nil {
+		return "", &UnknownCommandError{Command: cmd.Value}
+	}
+
+	j := i + 1
+	for j < len(words) && !words[j].Op {
+		j++
+	}
+	argWords := words[i+1 : j]
+
+	args := make([]string, len(argWords))
+	byValue := make(map[string]Word)
+	for k, w := range argWords {
+		args[k] = w.Value
+		if _, ok := byValue[w.Value]; !ok {
+			byValue[w.Value] = w
+		}
+	}
+
+	if opts.Skip != nil && opts.Skip(cmd.Value, args) {
+		return "", ErrSkipped
+	}
+	if grade, mappings := translator.Grade(t, args, opts.Mode); grade < opts.MinFidelity {
+		return "", &FidelityError{Command: cmd.Value, Fidelity: grade, Mappings: mappings}
+	}
+
+	parts := []string{quote(t.TargetTool())}
+	for _, arg := range t.Translate(args, opts.Mode) {
+		w, unchanged := byValue[arg]
+		if unchanged && keepsSpelling(w, opts.Shell) {
+			parts = append(parts, w.Text)
+			continue
+		}
+		if unchanged && w.Expands {
+			return "", &ExpansionError{Arg: arg}
+		}
+		// Globs are fine to quote, as the tool would have received them
+		// literally, but substitutions are not
+		if strings.ContainsAny(arg, "$`") {
+			for _, w := range argWords {
+				if w.Expands && strings.ContainsAny(w.Value, "$`") {
+					return "", &ExpansionError{Arg: arg}
+				}
+			}
+		}
+		parts = append(parts, quote(arg))
+	}
+
+	end := cmd.End
+	if len(argWords) > 0 {
+		end = argWords[len(argWords)-1].End
+	}
+	return line[:cmd.Start] + strings.Join(parts, " ") + line[end:], nil
+}
+
+// keepsSpelling reports whether w's source text means the same in shell.
+// POSIX shells read it as written; fish shares only plain words, globs,
+// ~ and simple $VAR references.
+func keepsSpelling(w Word, shell string) bool {
+	if shell != "fish" {
+		return true
+	}
+	if w.Quoted {
+		return false
+	}
+	for _, r := range w.Text {
+		if needsFishQuote(r) && !strings.ContainsRune("*?~$", r) {
+			return false
+		}
+	}
+	return true
+}
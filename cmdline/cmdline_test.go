@@ -0,0 +1,118 @@
+package cmdline
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kluzzebass/reflag/translator"
+
+	_ "github.com/kluzzebass/reflag/translator/all" // Register every translator
+)
+
+func TestTranslateLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		opts     Options
+		expected string
+	}{
+		{"simple", "ls -lt", Options{}, "eza -l --sort=modified --reverse"},
+		{"pipeline", "ls -lt | head", Options{}, "eza -l --sort=modified --reverse | head"},
+		{"assignment", "LC_ALL=C grep -i foo *.txt", Options{}, "LC_ALL=C rg -i foo *.txt"},
+		{"variable kept", `grep -r "$PAT" .`, Options{}, `rg "$PAT" .`},
+		{"quoted for sh", "grep -F 'a b'", Options{}, "rg -F 'a b'"},
+		{"quoted for fish", "grep -F 'it'\"'\"'s'", Options{Shell: "fish"}, `rg -F 'it\'s'`},
+		{"glob kept for fish", "grep -i foo *.go", Options{Shell: "fish"}, "rg -i foo *.go"},
+		{"variable kept for fish", "grep foo $HOME", Options{Shell: "fish"}, "rg foo $HOME"},
+		{"mode", "ls -G", Options{Mode: "bsd"}, "eza"},
+		{"selection", "grep foo", Options{Translators: []string{"search"}}, "rg foo"},
+		{"meets min fidelity", "grep -i foo", Options{MinFidelity: translator.Exact}, "rg -i foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TranslateLine(tt.line, tt.opts)
+			if err != nil {
+				t.Fatalf("TranslateLine(%q) error: %v", tt.line, err)
+			}
+			if got != tt.expected {
+				t.Errorf("TranslateLine(%q) = %q, want %q", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslateLineErrors(t *testing.T) {
+	var (
+		syntax    *SyntaxError
+		unknown   *UnknownCommandError
+		expansion *ExpansionError
+		fidelity  *FidelityError
+	)
+	tests := []struct {
+		name   string
+		line   string
+		opts   Options
+		target any
+	}{
+		{"unbalanced single quote", "grep 'foo", Options{}, &syntax},
+		{"unbalanced double quote", `ls "dir`, Options{}, &syntax},
+		{"unknown command", "make all", Options{}, &unknown},
+		{"escaped command", `\ls -l`, Options{}, &unknown},
+		{"not selected", "ls -l", Options{Translators: []string{"grep2rg"}}, &unknown},
+		{"expansion in fish", `grep foo "$dir"`, Options{Shell: "fish"}, &expansion},
+		{"below min fidelity", "grep -s foo", Options{MinFidelity: translator.Approximate}, &fidelity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TranslateLine(tt.line, tt.opts)
+			if err == nil {
+				t.Fatalf("TranslateLine(%q) = %q, want error", tt.line, got)
+			}
+			if !errors.As(err, tt.target) {
+				t.Errorf("TranslateLine(%q) error = %T %v, want %T", tt.line, err, err, tt.target)
+			}
+		})
+	}
+
+	for _, line := range []string{"", "  # comment", "FOO=1", "| ls"} {
+		if _, err := TranslateLine(line, Options{}); !errors.Is(err, ErrNoCommand) {
+			t.Errorf("TranslateLine(%q) error = %v, want ErrNoCommand", line, err)
+		}
+	}
+
+	skip := func(tool string, args []string) bool { return tool == "grep" && len(args) > 0 && args[0] == "-P" }
+	if _, err := TranslateLine("grep -P 'a(?=b)' f", Options{Skip: skip}); !errors.Is(err, ErrSkipped) {
+		t.Errorf("skipped command error = %v, want ErrSkipped", err)
+	}
+	if got, err := TranslateLine("grep -F x f", Options{Skip: skip}); err != nil || got != "rg -F x f" {
+		t.Errorf("command not skipped = %q, %v", got, err)
+	}
+
+	if _, err := TranslateLine("ls", Options{Shell: "csh"}); err == nil {
+		t.Error("unsupported shell should be an error")
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input, shell, expected string
+	}{
+		{"plain", "sh", "plain"},
+		{"", "fish", "''"},
+		{"a b", "bash", "'a b'"},
+		{"it's", "sh", `'it'"'"'s'`},
+		{"it's", "fish", `'it\'s'`},
+		{`a\b`, "fish", `'a\\b'`},
+		{"=cmd", "zsh", "'=cmd'"},
+		{"50%", "sh", "50%"},
+		{"50%", "fish", "'50%'"},
+	}
+
+	for _, tt := range tests {
+		if got := Quote(tt.input, tt.shell); got != tt.expected {
+			t.Errorf("Quote(%q, %q) = %q, want %q", tt.input, tt.shell, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package cmdline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Word is a word or operator from a POSIX shell command line
+type Word struct {
+	Text    string // source text, including any quoting
+	Value   string // text with quoting removed
+	Start   int    // byte offset of the first character in the line
+	End     int    // byte offset just past the last character
+	Op      bool   // control or redirection operator (or an IO number)
+	Quoted  bool   // contains quotes or backslash escapes
+	Expands bool   // contains expansions or globs, so Value is only approximate
+}
+
+// SyntaxError reports a command line that can't be split, such as one with
+// an unbalanced quote
+type SyntaxError struct {
+	Offset int    // byte offset of the construct that isn't closed
+	Msg    string // e.g. "unterminated single quote"
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+// Characters that end an unquoted word
+const wordBreaks = " \t\n;&|()<>"
+
+// Multi-character operators, longest first
+var shellOperators = []string{"&&", "||", ";;", "<<", ">>", "<&", ">&", "<>", ">|", "&>", "|&"}
+
+// Split splits a command line into words and operators following
+// POSIX quoting rules. Expansions are kept verbatim in the word value.
+func Split(line string) ([]Word, error) {
+	var words []Word
+	i := 0
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '\\' && i+1 < len(line) && line[i+1] == '\n':
+			i += 2 // line continuation
+		case c == '#':
+			for i < len(line) && line[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte(wordBreaks, c) >= 0:
+			n := 1
+			for _, op := range shellOperators {
+				if strings.HasPrefix(line[i:], op) {
+					n = len(op)
+					break
+				}
+			}
+			words = append(words, Word{Text: line[i : i+n], Value: line[i : i+n], Start: i, End: i + n, Op: true})
+			i += n
+		default:
+			w, err := lexWord(line, i)
+			if err != nil {
+				return nil, err
+			}
+			// Digits directly followed by a redirection are an IO number
+			if w.End < len(line) && (line[w.End] == '<' || line[w.End] == '>') &&
+				!w.Quoted && strings.Trim(w.Text, "0123456789") == "" {
+				w.Op = true
+			}
+			words = append(words, w)
+			i = w.End
+		}
+	}
+	return words, nil
+}
+
+// lexWord reads one word starting at start
+func lexWord(line string, start int) (Word, error) {
+	var b strings.Builder
+	w := Word{Start: start}
+	i := start
+	for i < len(line) && strings.IndexByte(wordBreaks, line[i]) < 0 {
+		c := line[i]
+		switch c {
+		case '\\':
+			w.Quoted = true
+			if i+1 < len(line) && line[i+1] != '\n' {
+				b.WriteByte(line[i+1])
+			}
+			i += 2
+		case '\'':
+			w.Quoted = true
+			j := strings.IndexByte(line[i+1:], '\'')
+			if j < 0 {
+				return w, &SyntaxError{Offset: i, Msg: "unterminated single quote"}
+			}
+			b.WriteString(line[i+1 : i+1+j])
+			i += j + 2
+		case '"':
+			w.Quoted = true
+			open := i
+			i++
+			for {
+				if i >= len(line) {
+					return w, &SyntaxError{Offset: open, Msg: "unterminated double quote"}
+				}
+				c := line[i]
+				if c == '"' {
+					i++
+					break
+				}
+				if c == '\\' && i+1 < len(line) && strings.IndexByte("$`\"\\\n", line[i+1]) >= 0 {
+					if line[i+1] != '\n' {
+						b.WriteByte(line[i+1])
+					}
+					i += 2
+					continue
+				}
+				if c == '$' || c == '`' {
+					n, err := skipExpansion(line, i)
+					if err != nil {
+						return w, err
+					}
+					w.Expands = true
+					b.WriteString(line[i:n])
+					i = n
+					continue
+				}
+				b.WriteByte(c)
+				i++
+			}
+		case '$', '`':
+			n, err := skipExpansion(line, i)
+			if err != nil {
+				return w, err
+			}
+			w.Expands = true
+			b.WriteString(line[i:n])
+			i = n
+		case '*', '?', '[', '~', '{':
+			w.Expands = true
+			b.WriteByte(c)
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	if i > len(line) {
+		i = len(line)
+	}
+	w.End = i
+	w.Text = line[start:i]
+	w.Value = b.String()
+	return w, nil
+}
+
+// skipExpansion returns the offset just past the $ or ` expansion at i
+func skipExpansion(line string, i int) (int, error) {
+	if line[i] == '`' {
+		for j := i + 1; j < len(line); j++ {
+			switch line[j] {
+			case '\\':
+				j++
+			case '`':
+				return j + 1, nil
+			}
+		}
+		return 0, &SyntaxError{Offset: i, Msg: "unterminated backquote"}
+	}
+
+	if i+1 >= len(line) {
+		return i + 1, nil
+	}
+	switch c := line[i+1]; {
+	case c == '(' || c == '{':
+		closer := byte(')')
+		if c == '{' {
+			closer = '}'
+		}
+		depth := 0
+		for j := i + 1; j < len(line); j++ {
+			switch line[j] {
+			case '\\':
+				j++
+			case '\'':
+				k := strings.IndexByte(line[j+1:], '\'')
+				if k < 0 {
+					return 0, &SyntaxError{Offset: j, Msg: "unterminated single quote"}
+				}
+				j += k + 1
+			case c:
+				depth++
+			case closer:
+				depth--
+				if depth == 0 {
+					return j + 1, nil
+				}
+			}
+		}
+		return 0, &SyntaxError{Offset: i, Msg: "unterminated " + string(line[i:i+2])}
+	case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		j := i + 1
+		for j < len(line) && (line[j] == '_' || line[j] >= 'A' && line[j] <= 'Z' ||
+			line[j] >= 'a' && line[j] <= 'z' || line[j] >= '0' && line[j] <= '9') {
+			j++
+		}
+		return j, nil
+	case strings.IndexByte("@*#?$!-0123456789", c) >= 0:
+		return i + 2, nil
+	}
+	// A lone $ is literal
+	return i + 1, nil
+}
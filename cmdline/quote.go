@@ -0,0 +1,64 @@
+package cmdline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Quote quotes s as a single word for shell ("sh", "bash", "zsh", "ksh" or
+// "fish"; "" means sh). Words made only of characters with no special
+// meaning are returned as they are.
+func Quote(s, shell string) string {
+	q, err := quoter(shell)
+	if err != nil {
+		q = quotePOSIX
+	}
+	return q(s)
+}
+
+// quoter returns the quoting function for shell
+func quoter(shell string) (func(string) string, error) {
+	switch shell {
+	case "", "sh", "bash", "zsh", "ksh":
+		return quotePOSIX, nil
+	case "fish":
+		return quoteFish, nil
+	}
+	return nil, fmt.Errorf("unsupported shell %q (expected sh, bash, zsh, ksh or fish)", shell)
+}
+
+// quotePOSIX single-quotes s, spelling embedded quotes as '"'"'. A leading =
+// is quoted too, as zsh expands =cmd to a path.
+func quotePOSIX(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, needsQuote) >= 0 || s[0] == '=' {
+		return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+	}
+	return s
+}
+
+// quoteFish single-quotes s; fish only recognizes \' and \\ inside single quotes
+func quoteFish(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, needsFishQuote) >= 0 {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	return s
+}
+
+func needsQuote(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("-_./:=,+@%", r)
+}
+
+// needsFishQuote is needsQuote without %, which older fish expands
+func needsFishQuote(r rune) bool {
+	return r == '%' || needsQuote(r)
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// compareEnv is added to the environment of compared commands so neither
+// colors its output or starts a pager
+var compareEnv = []string{"NO_COLOR=1", "CLICOLOR=0", "TERM=dumb", "PAGER=cat"}
+
+// runResult is what a compared command printed and how it ended
+type runResult struct {
+	argv     []string
+	stdout   string
+	exitCode int // -1 when the command couldn't be started or timed out
+	timedOut bool
+	err      error
+}
+
+// runCompared runs argv with stdin from /dev/null, no colors and timeout
+func runCompared(argv []string, timeout time.Duration) runResult {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(withoutEnv(os.Environ(), "CLICOLOR_FORCE", "FORCE_COLOR"), compareEnv...)
+	cmd.WaitDelay = time.Second
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	err := cmd.Run()
+
+	r := runResult{argv: argv, stdout: stdout.String(), exitCode: cmd.ProcessState.ExitCode()}
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		r.timedOut = true
+		r.exitCode = -1
+	case err != nil && !errors.As(err, &exitErr):
+		r.err = err
+		r.exitCode = -1
+	}
+	return r
+}
+
+// withoutEnv returns env without the given variables
+func withoutEnv(env []string, names ...string) []string {
+	var out []string
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if !slices.Contains(names, name) {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// ansiRe matches terminal escape sequences: CSI (colors, cursor movement)
+// and OSC (hyperlinks, titles)
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+// normalizeOutput makes the outputs of a tool and its replacement
+// comparable: escape sequences are removed, box drawing becomes spaces, runs
+// of whitespace become one space, and blank lines are dropped
+func normalizeOutput(s string) []string {
+	s = ansiRe.ReplaceAllString(s, "")
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Map(func(r rune) rune {
+			if r >= 0x2500 && r <= 0x257f { // box drawing
+				return ' '
+			}
+			return r
+		}, line)
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// diffOp is one line of a line diff: ' ' in both, '-' only in a, '+' only in b
+type diffOp struct {
+	kind byte
+	a, b string // the line in a and in b; both are set for ' '
+}
+
+// maxDiffCells bounds the longest-common-subsequence table; larger inputs
+// are diffed by their common prefix and suffix only
+const maxDiffCells = 4_000_000
+
+// diffLines returns a minimal line diff of a and b
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', a: line, b: line})
+	}
+	ops = append(ops, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', a: line, b: line})
+	}
+	return ops
+}
+
+// diffMiddle diffs a and b by their longest common subsequence
+func diffMiddle(a, b []string) []diffOp {
+	var ops []diffOp
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: '-', a: line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: '+', b: line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', a: a[i], b: b[j]})
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{kind: '+', b: b[j]})
+			j++
+		default:
+			ops = append(ops, diffOp{kind: '-', a: a[i]})
+			i++
+		}
+	}
+	return ops
+}
+
+// writeUnified prints ops as a unified diff with three lines of context
+func writeUnified(w io.Writer, aName, bName string, ops []diffOp) {
+	const contextLines = 3
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", aName, bName)
+
+	// Line numbers in a and b at the start of each op
+	aLine := make([]int, len(ops)+1)
+	bLine := make([]int, len(ops)+1)
+	for k, op := range ops {
+		aLine[k+1], bLine[k+1] = aLine[k], bLine[k]
+		if op.kind != '+' {
+			aLine[k+1]++
+		}
+		if op.kind != '-' {
+			bLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+		// Extend the hunk while changes are within 2*contextLines lines
+		start := max(k-contextLines, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*contextLines {
+				end = min(end+contextLines, len(ops))
+				break
+			}
+			end = next
+		}
+
+		fmt.Fprintf(w, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]-aLine[start]), hunkRange(bLine[start], bLine[end]-bLine[start]))
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case ' ':
+				fmt.Fprintf(w, " %s\n", op.a)
+			case '-':
+				fmt.Fprintf(w, "-%s\n", op.a)
+			case '+':
+				fmt.Fprintf(w, "+%s\n", op.b)
+			}
+		}
+		k = end
+	}
+}
+
+// writeSideBySide prints ops in two columns of a total width, marking lines
+// only on the left with <, only on the right with > and changed lines with |,
+// as diff -y does. Adjacent removals and additions are paired up.
+func writeSideBySide(w io.Writer, aName, bName string, ops []diffOp, width int) {
+	col := max((width-3)/2, 10)
+	row := func(left, mark, right string) {
+		fmt.Fprintf(w, "%s %s %s\n", fitColumn(left, col), mark, strings.TrimRightFunc(right, unicode.IsSpace))
+	}
+	row(aName, " ", bName)
+	row(strings.Repeat("-", col), " ", strings.Repeat("-", col))
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			row(ops[k].a, " ", ops[k].b)
+			k++
+			continue
+		}
+		var removed, added []string
+		for ; k < len(ops) && ops[k].kind != ' '; k++ {
+			if ops[k].kind == '-' {
+				removed = append(removed, ops[k].a)
+			} else {
+				added = append(added, ops[k].b)
+			}
+		}
+		for n := 0; n < max(len(removed), len(added)); n++ {
+			switch {
+			case n < len(removed) && n < len(added):
+				row(removed[n], "|", added[n])
+			case n < len(removed):
+				row(removed[n], "<", "")
+			default:
+				row("", ">", added[n])
+			}
+		}
+	}
+}
+
+// fitColumn pads or truncates s to width runes
+func fitColumn(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// describeExit formats how a compared command ended
+func describeExit(r runResult, timeout time.Duration) string {
+	switch {
+	case r.timedOut:
+		return fmt.Sprintf("killed after %s", timeout)
+	case r.err != nil:
+		return r.err.Error()
+	}
+	return fmt.Sprintf("exit %d", r.exitCode)
+}
+
+// runCompare implements compare [--timeout=DURATION] [--side-by-side]
+// [--width=N] [--raw] <source> [args...]. It exits 0 when the outputs and
+// exit codes match and exitDiffers when they don't.
+func runCompare(g globalOptions, args []string) {
+	mode := g.mode
+	timeout := 10 * time.Second
+	sideBySide := false
+	raw := false
+	width := 0
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		var err error
+		switch {
+		case strings.HasPrefix(arg, "--timeout="):
+			timeout, err = time.ParseDuration(strings.TrimPrefix(arg, "--timeout="))
+			if err == nil && timeout <= 0 {
+				err = fmt.Errorf("timeout must be positive")
+			}
+		case strings.HasPrefix(arg, "--width="):
+			width, err = strconv.Atoi(strings.TrimPrefix(arg, "--width="))
+		case arg == "--side-by-side" || arg == "-y":
+			sideBySide = true
+		case arg == "--unified":
+			sideBySide = false
+		case arg == "--raw":
+			raw = true
+		default:
+			err = fmt.Errorf("unknown compare option %q", arg)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: reflag compare [options] <source> [args...] (see reflag compare --help)")
+		os.Exit(exitError)
+	}
+
+	source, sourceArgs := args[0], args[1:]
+	t := translatorsBySource()[source]
+	if t == nil {
+		fmt.Fprintf(os.Stderr, "error: no translator registered for %s\n", source)
+		os.Exit(exitRefused)
+	}
+	if effect := translator.SideEffect(t, sourceArgs, mode); effect != "" {
+		fmt.Fprintf(os.Stderr, "error: refusing to compare %s: %s\n", source, effect)
+		os.Exit(exitRefused)
+	}
+	for _, tool := range []string{source, t.TargetTool()} {
+		if _, err := exec.LookPath(tool); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s is not installed\n", tool)
+			os.Exit(exitError)
+		}
+	}
+
+	a := runCompared(append([]string{source}, sourceArgs...), timeout)
+	b := runCompared(append([]string{t.TargetTool()}, t.Translate(sourceArgs, mode)...), timeout)
+	aName := joinQuoted(a.argv[0], a.argv[1:])
+	bName := joinQuoted(b.argv[0], b.argv[1:])
+	fmt.Printf("%s: %s\n", aName, describeExit(a, timeout))
+	fmt.Printf("%s: %s\n", bName, describeExit(b, timeout))
+
+	var aLines, bLines []string
+	if raw {
+		aLines, bLines = splitLines(a.stdout), splitLines(b.stdout)
+	} else {
+		aLines, bLines = normalizeOutput(a.stdout), normalizeOutput(b.stdout)
+	}
+	ops := diffLines(aLines, bLines)
+	fmt.Println()
+	if !slices.ContainsFunc(ops, func(op diffOp) bool { return op.kind != ' ' }) {
+		if a.exitCode != b.exitCode {
+			fmt.Println("Outputs match, exit codes differ.")
+			os.Exit(exitDiffers)
+		}
+		fmt.Println("Outputs match.")
+		return
+	}
+
+	if sideBySide {
+		if width <= 0 {
+			width, _ = strconv.Atoi(os.Getenv("COLUMNS"))
+		}
+		if width <= 0 {
+			width = 160
+		}
+		writeSideBySide(os.Stdout, aName, bName, ops, width)
+	} else {
+		writeUnified(os.Stdout, aName, bName, ops)
+	}
+	os.Exit(exitDiffers)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNormalizeOutput(t *testing.T) {
+	in := "\x1b[1;34mdir\x1b[0m   file\n\n\x1b]8;;file:///x\x1b\\link\x1b]8;;\x1b\\\n│ /dev  │ 10G │\n  trailing  \t\n"
+	want := []string{"dir file", "link", "/dev 10G", "trailing"}
+	if got := normalizeOutput(in); !slices.Equal(got, want) {
+		t.Errorf("normalizeOutput = %q, want %q", got, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want string // one kind per op
+	}{
+		{"equal", []string{"x", "y"}, []string{"x", "y"}, "  "},
+		{"added", []string{"x"}, []string{"x", "y"}, " +"},
+		{"removed", []string{"x", "y", "z"}, []string{"x", "z"}, " - "},
+		{"changed", []string{"a", "b", "c"}, []string{"a", "B", "c"}, " -+ "},
+		{"reordered", []string{"a", "b", "c"}, []string{"c", "a", "b"}, "+  -"},
+		{"empty", nil, []string{"x"}, "+"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []byte
+			for _, op := range diffLines(tt.a, tt.b) {
+				kinds = append(kinds, op.kind)
+			}
+			if string(kinds) != tt.want {
+				t.Errorf("diffLines(%q, %q) = %q, want %q", tt.a, tt.b, kinds, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteUnified(t *testing.T) {
+	a := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12"}
+	b := []string{"1", "two", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13"}
+
+	var buf bytes.Buffer
+	writeUnified(&buf, "ls", "eza", diffLines(a, b))
+	want := `--- ls
++++ eza
+@@ -1,5 +1,5 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if buf.String() != want {
+		t.Errorf("writeUnified =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteSideBySide(t *testing.T) {
+	var buf bytes.Buffer
+	writeSideBySide(&buf, "ls", "eza", diffLines([]string{"a", "b", "c"}, []string{"a", "B", "c", "d"}), 23)
+	want := `ls           eza
+----------   ----------
+a            a
+b          | B
+c            c
+           > d
+`
+	if buf.String() != want {
+		t.Errorf("writeSideBySide =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if got := fitColumn("abcdefghijkl", 5); got != "abcd…" {
+		t.Errorf("fitColumn = %q", got)
+	}
+}
+
+func TestRunCompared(t *testing.T) {
+	r := runCompared([]string{"sh", "-c", `echo "$NO_COLOR"; exit 3`}, 5*time.Second)
+	if r.stdout != "1\n" || r.exitCode != 3 || r.timedOut || r.err != nil {
+		t.Errorf("runCompared = %+v", r)
+	}
+
+	r = runCompared([]string{"sh", "-c", "sleep 5"}, 50*time.Millisecond)
+	if !r.timedOut || r.exitCode != -1 {
+		t.Errorf("runCompared with timeout = %+v", r)
+	}
+
+	r = runCompared([]string{"/nonexistent/tool"}, time.Second)
+	if r.err == nil || r.exitCode != -1 {
+		t.Errorf("runCompared of a missing tool = %+v", r)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// config holds settings from the reflag config file. The file has one
+// "key = value" setting per line; # starts a comment.
+//
+//	# ~/.config/reflag/config
+//	translators = default -ls2eza +pagers
+//	validate = warn
+//	min-fidelity = approximate
+//	route = tool=ls flag=--dired
+//	pipe = tty-or-compatible
+//	pipe.ps2procs = tty-only
+type config struct {
+	// translators holds selection expressions (see translator.Select)
+	// applied before any given on the command line. Repeated lines append.
+	translators []string
+
+	// validate is the policy for flags the target's --help doesn't list
+	// (off, warn or drop); $REFLAG_VALIDATE and --validate override it
+	validate string
+
+	// minFidelity is the lowest translation grade to emit; commands
+	// graded below it run untranslated
+	minFidelity translator.Fidelity
+
+	// rules send matching commands to the source tool untranslated, in
+	// the order given (see rule)
+	rules []rule
+
+	// pipe is the policy for commands whose output doesn't go to a
+	// terminal (always, tty-only or tty-or-compatible); pipeFor overrides
+	// it per translator
+	pipe    string
+	pipeFor map[string]string
+}
+
+// configPath returns the config file location: $REFLAG_CONFIG, or
+// reflag/config under $XDG_CONFIG_HOME (default ~/.config)
+func configPath() string {
+	if p := os.Getenv("REFLAG_CONFIG"); p != "" {
+		return p
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "reflag", "config")
+}
+
+// loadConfig reads the config file. A missing file gives an empty config.
+func loadConfig() (*config, error) {
+	path := configPath()
+	if path == "" {
+		return &config{}, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &config{}, nil
+	}
+	if err != nil {
+		return &config{}, err
+	}
+	defer f.Close()
+	return parseConfig(f, path)
+}
+
+// configStamp tells versions of the config file apart
+type configStamp struct {
+	path  string
+	mtime int64
+	size  int64
+}
+
+// configCache holds the config file as last read by userConfig
+var configCache struct {
+	sync.Mutex
+	stamp  configStamp
+	config *config
+}
+
+// userConfig loads the config file, and loads it again only when it has
+// changed; reflag serve outlives edits to it. A broken file is reported as a
+// warning and otherwise ignored, so reflag keeps working.
+func userConfig() *config {
+	stamp := configStamp{path: configPath()}
+	if info, err := os.Stat(stamp.path); err == nil {
+		stamp.mtime, stamp.size = info.ModTime().UnixNano(), info.Size()
+	}
+
+	configCache.Lock()
+	defer configCache.Unlock()
+	if configCache.config == nil || configCache.stamp != stamp {
+		c, err := loadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		configCache.stamp, configCache.config = stamp, c
+	}
+	return configCache.config
+}
+
+// parseConfig parses config file contents; name is used in error messages
+func parseConfig(r io.Reader, name string) (*config, error) {
+	c := &config{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return c, fmt.Errorf("%s:%d: expected key = value", name, n)
+		}
+		key = strings.TrimSpace(key)
+		switch key {
+		case "translators":
+			c.translators = append(c.translators, splitList(value)...)
+		case "validate":
+			c.validate = strings.TrimSpace(value)
+			if err := checkPolicy(c.validate); err != nil {
+				return c, fmt.Errorf("%s:%d: %v", name, n, err)
+			}
+		case "min-fidelity":
+			f, err := translator.ParseFidelity(strings.TrimSpace(value))
+			if err != nil {
+				return c, fmt.Errorf("%s:%d: %v", name, n, err)
+			}
+			c.minFidelity = f
+		case "route":
+			r, err := parseRule(value, fmt.Sprintf("%s:%d", name, n))
+			if err != nil {
+				return c, fmt.Errorf("%s:%d: %v", name, n, err)
+			}
+			c.rules = append(c.rules, r)
+		case "pipe":
+			c.pipe = strings.TrimSpace(value)
+			if err := checkPipePolicy(c.pipe); err != nil {
+				return c, fmt.Errorf("%s:%d: %v", name, n, err)
+			}
+		default:
+			tr, ok := strings.CutPrefix(key, "pipe.")
+			if !ok {
+				return c, fmt.Errorf("%s:%d: unknown setting %q", name, n, key)
+			}
+			if translator.GetByName(tr) == nil {
+				return c, fmt.Errorf("%s:%d: unknown translator %q", name, n, tr)
+			}
+			policy := strings.TrimSpace(value)
+			if err := checkPipePolicy(policy); err != nil {
+				return c, fmt.Errorf("%s:%d: %v", name, n, err)
+			}
+			if c.pipeFor == nil {
+				c.pipeFor = make(map[string]string)
+			}
+			c.pipeFor[tr] = policy
+		}
+	}
+	return c, sc.Err()
+}
+
+// splitList splits a list separated by commas and/or whitespace
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
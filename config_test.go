@@ -0,0 +1,55 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		translators []string
+		validate    string
+		wantErr     string
+	}{
+		{"empty", "", nil, "", ""},
+		{"comments and blanks", "# reflag\n\n   # indented\n", nil, "", ""},
+		{"spaces", "translators = ls2eza grep2rg\n", []string{"ls2eza", "grep2rg"}, "", ""},
+		{"commas and modifiers", "translators=default,-ls2eza, +pagers # tweaks\n", []string{"default", "-ls2eza", "+pagers"}, "", ""},
+		{"repeated lines append", "translators = search\ntranslators = +*2moor\n", []string{"search", "+*2moor"}, "", ""},
+		{"validate", "validate = drop\n", nil, "drop", ""},
+		{"bad validate", "validate = maybe\n", nil, "", `test:1: unknown validation policy "maybe" (expected off, warn or drop)`},
+		{"min-fidelity", "min-fidelity = approximate\n", nil, "", ""},
+		{"bad min-fidelity", "min-fidelity = perfect\n", nil, "", `test:1: unknown fidelity "perfect" (expected exact, approximate or lossy)`},
+		{"route", "route = tool=ls flag=--dired\n", nil, "", ""},
+		{"bad route", "route = tool=ls colour=always\n", nil, "", `test:1: unknown route condition "colour"`},
+		{"pipe", "pipe = tty-only\npipe.ls2eza = tty-or-compatible\n", nil, "", ""},
+		{"bad pipe", "pipe = never\n", nil, "", `test:1: unknown pipe policy "never" (expected always, tty-only or tty-or-compatible)`},
+		{"pipe for unknown translator", "pipe.ls2lsd = always\n", nil, "", `test:1: unknown translator "ls2lsd"`},
+		{"unknown key", "colour = always\n", nil, "", `test:1: unknown setting "colour"`},
+		{"missing equals", "\ntranslators ls2eza\n", nil, "", "test:2: expected key = value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseConfig(strings.NewReader(tt.input), "test")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(c.translators, tt.translators) {
+				t.Errorf("translators = %q, want %q", c.translators, tt.translators)
+			}
+			if c.validate != tt.validate {
+				t.Errorf("validate = %q, want %q", c.validate, tt.validate)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// savedAliasVar names the sh variable holding an alias --init removed for tool
+func savedAliasVar(tool string) string {
+	return "__reflag_alias_" + strings.ReplaceAll(tool, "-", "_")
+}
+
+// savedFunctionName names the fish function holding a copy of a function
+// --init erased for tool
+func savedFunctionName(tool string) string {
+	return "__reflag_saved_" + tool
+}
+
+// writeFishSaveFunction emits fish code copying an existing function for tool
+// (an alias, or one of fish's own like ls) before --init erases it. A copy
+// already saved by an earlier --init, or reflag's own wrapper, is left alone.
+func writeFishSaveFunction(w io.Writer, tool string) {
+	saved := savedFunctionName(tool)
+	fmt.Fprintf(w, "if functions -q %s; and not functions -q %s\n", tool, saved)
+	fmt.Fprintf(w, "    string match -q '*__reflag*' -- (functions %s); or functions -c %s %s\n", tool, tool, saved)
+	fmt.Fprintln(w, "end")
+}
+
+// printDeinit emits code removing what printInit generates for the same
+// arguments, restoring aliases and functions it replaced
+func printDeinit(w io.Writer, shell string, selection []string, opts initOptions) error {
+	names := selectTranslators(selection)
+	if err := checkPortable(shell, opts); err != nil {
+		return err
+	}
+	if opts.portable {
+		names, _ = portableTranslators(names)
+	} else if !opts.all {
+		// Leave alone what init skipped, such as the user's own less()
+		// where moor isn't installed
+		names, _ = installedTranslators(names)
+	}
+
+	if opts.widget != "" {
+		return writeWidgetDeinit(w, shell, opts.widget)
+	}
+	if err := checkWrap(shell, opts); err != nil {
+		return err
+	}
+	tools := make([]string, len(names))
+	for i, name := range names {
+		tools[i] = translator.GetByName(name).SourceTool()
+	}
+	tools = append(tools, opts.wrap...)
+
+	switch shell {
+	case "fish":
+		writeFishDeinit(w, tools)
+	case "nu", "elvish", "xonsh":
+		return fmt.Errorf("--deinit is not available for %s; start a new shell instead", shell)
+	default: // bash, zsh, sh, ksh
+		writeShDeinit(w, tools)
+	}
+	return nil
+}
+
+// writeShDeinit removes sh-family wrappers for tools. Shells differ in how alias prints
+// a definition: bash includes the alias keyword, zsh and dash don't.
+func writeShDeinit(w io.Writer, tools []string) {
+	fmt.Fprintln(w, "# reflag shell deinit - run with: eval \"$(reflag --deinit)\"")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "__reflag_realias() {")
+	fmt.Fprintln(w, "    case $1 in")
+	fmt.Fprintln(w, "        '') ;;")
+	fmt.Fprintln(w, `        alias\ *) eval "$1" ;;`)
+	fmt.Fprintln(w, `        *) eval "alias $1" ;;`)
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	for _, tool := range tools {
+		saved := savedAliasVar(tool)
+		fmt.Fprintf(w, "unset -f %s 2>/dev/null || :\n", tool)
+		fmt.Fprintf(w, "__reflag_realias \"${%s-}\"\n", saved)
+		fmt.Fprintf(w, "unset %s\n", saved)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "unset -f __reflag_off __reflag_run __reflag_wrap __reflag_add __reflag_exec __reflag_realias __reflag_client __reflag_spawn_run reflag 2>/dev/null || :")
+	fmt.Fprintln(w, "unset __reflag_a __reflag_src __reflag_tgt __reflag_names __reflag_out __reflag_status __reflag_sock __reflag_argv __reflag_tty")
+	fmt.Fprintln(w, "unset __reflag_flags __reflag_sort __reflag_rev __reflag_arg __reflag_f __reflag_c __reflag_w __reflag_n __reflag_opts")
+}
+
+// writeFishDeinit removes fish wrappers for tools
+func writeFishDeinit(w io.Writer, tools []string) {
+	fmt.Fprintln(w, "# reflag shell deinit - run with: reflag --deinit fish | source")
+	fmt.Fprintln(w)
+	for _, tool := range tools {
+		saved := savedFunctionName(tool)
+		fmt.Fprintf(w, "functions -e %s\n", tool)
+		fmt.Fprintf(w, "if functions -q %s\n", saved)
+		fmt.Fprintf(w, "    functions -c %s %s\n", saved, tool)
+		fmt.Fprintf(w, "    functions -e %s\n", saved)
+		fmt.Fprintln(w, "end")
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "functions -e __reflag_off __reflag_run __reflag_wrap reflag")
+}
+
+// writeWidgetDeinit removes the key bindings and widgets from writeWidgetInit,
+// putting Enter back to plain accept-line for mode "enter"
+func writeWidgetDeinit(w io.Writer, shell string, mode string) error {
+	switch shell {
+	case "zsh":
+		fmt.Fprintln(w, "# reflag widget deinit - run with: eval \"$(reflag --deinit zsh --widget)\"")
+		if mode == "enter" {
+			fmt.Fprintln(w, "zle -A .accept-line accept-line")
+			fmt.Fprintln(w, "unset -f __reflag_accept_line")
+		}
+		fmt.Fprintln(w, "bindkey -r '^Xt'")
+		fmt.Fprintln(w, "zle -D __reflag_rewrite 2>/dev/null")
+		fmt.Fprintln(w, "unset -f __reflag_rewrite")
+	case "bash":
+		fmt.Fprintln(w, "# reflag widget deinit - run with: eval \"$(reflag --deinit bash --widget)\"")
+		if mode == "enter" {
+			fmt.Fprintln(w, `bind '"\C-m": accept-line'`)
+		}
+		fmt.Fprintln(w, `bind -r '\C-xt'`)
+		fmt.Fprintln(w, "unset -f __reflag_rewrite")
+	case "fish":
+		fmt.Fprintln(w, "# reflag widget deinit - run with: reflag --deinit fish --widget | source")
+		if mode == "enter" {
+			fmt.Fprintln(w, `bind \r execute`)
+		}
+		fmt.Fprintln(w, `bind -e \cxt`)
+		fmt.Fprintln(w, "functions -e __reflag_rewrite")
+	default:
+		return fmt.Errorf("line rewriting widgets are only available for bash, zsh and fish")
+	}
+	return nil
+}
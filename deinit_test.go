@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPrintDeinit(t *testing.T) {
+	tests := []struct {
+		shell    string
+		opts     initOptions
+		contains []string
+	}{
+		{
+			shell: "bash",
+			contains: []string{
+				"unset -f ls 2>/dev/null || :\n__reflag_realias \"${__reflag_alias_ls-}\"\nunset __reflag_alias_ls\n",
+				"unset -f __reflag_off __reflag_run __reflag_wrap __reflag_add __reflag_exec __reflag_realias __reflag_client __reflag_spawn_run reflag 2>/dev/null || :\n",
+				"__reflag_status __reflag_sock __reflag_argv __reflag_tty\n",
+			},
+		},
+		{
+			shell: "fish",
+			contains: []string{
+				"functions -e ls\nif functions -q __reflag_saved_ls\n    functions -c __reflag_saved_ls ls\n",
+				"functions -e __reflag_off __reflag_run __reflag_wrap reflag\n",
+			},
+		},
+		{
+			shell: "zsh",
+			opts:  initOptions{widget: "enter"},
+			contains: []string{
+				"zle -A .accept-line accept-line\n",
+				"bindkey -r '^Xt'\n",
+			},
+		},
+		{
+			shell: "bash",
+			opts:  initOptions{widget: "key"},
+			contains: []string{
+				`bind -r '\C-xt'` + "\n",
+				"unset -f __reflag_rewrite\n",
+			},
+		},
+		{
+			shell: "sh",
+			opts:  initOptions{portable: true},
+			contains: []string{
+				"unset -f ls 2>/dev/null || :\n",
+				"unset __reflag_flags __reflag_sort __reflag_rev",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := tt.opts
+			opts.all = true
+			if err := printDeinit(&buf, tt.shell, nil, opts); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("printDeinit(%s) missing %q in:\n%s", tt.shell, want, out)
+				}
+			}
+		})
+	}
+
+	if err := printDeinit(&bytes.Buffer{}, "nu", nil, initOptions{}); err == nil {
+		t.Error("--deinit should be rejected for nu")
+	}
+}
+
+func TestPrintDeinitSelection(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printDeinit(&buf, "bash", []string{"+dig2doggo", "-ls2eza"}, initOptions{all: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "unset -f dig ") {
+		t.Errorf("+dig2doggo should remove the dig wrapper:\n%s", out)
+	}
+	if strings.Contains(out, "unset -f ls ") {
+		t.Errorf("-ls2eza should leave ls alone:\n%s", out)
+	}
+}
+
+func TestPrintDeinitSkipsMissingTargets(t *testing.T) {
+	t.Setenv("PATH", fakeBin(t, "eza"))
+
+	var buf bytes.Buffer
+	if err := printDeinit(&buf, "bash", []string{"ls2eza", "less2moor"}, initOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "unset -f ls ") {
+		t.Errorf("deinit should remove the ls wrapper:\n%s", out)
+	}
+	if strings.Contains(out, "unset -f less ") {
+		t.Errorf("deinit should leave less alone without moor on PATH:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := printDeinit(&buf, "bash", []string{"less2moor"}, initOptions{all: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "unset -f less ") {
+		t.Errorf("--all should remove the less wrapper:\n%s", buf.String())
+	}
+}
+
+// TestShDeinit loads --init twice over an existing alias, then --deinit, and
+// checks the wrappers are gone and the alias is back
+func TestShDeinit(t *testing.T) {
+	// Put eza on PATH so init wraps ls
+	t.Setenv("PATH", fakeBin(t, "eza")+string(os.PathListSeparator)+os.Getenv("PATH"))
+	for _, sh := range posixShells {
+		t.Run(sh.name, func(t *testing.T) {
+			path, err := exec.LookPath(sh.cmd[0])
+			if err != nil {
+				t.Skipf("%s not installed", sh.cmd[0])
+			}
+			var init, deinit bytes.Buffer
+			printInit(&init, sh.init, nil, initOptions{})
+			printDeinit(&deinit, sh.init, nil, initOptions{})
+			if !strings.Contains(init.String(), "ls() {") {
+				t.Fatalf("init didn't wrap ls:\n%s", init.String())
+			}
+			// Run a wrapped command first, so the wrapper's globals are set
+			rc := filepath.Join(t.TempDir(), "rc.sh")
+			script := "alias ls='ls -X'\n" + init.String() + init.String() + "ls >/dev/null 2>&1\n" + deinit.String()
+			if err := os.WriteFile(rc, []byte(script), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			check := `. "$0" && alias ls
+for f in __reflag_run __reflag_client __reflag_spawn_run; do
+    if command -v $f >/dev/null; then echo "leftover $f"; fi
+done
+for v in __reflag_sock __reflag_argv __reflag_tty; do
+    eval "if [ -n \"\${$v+x}\" ]; then echo \"leftover $v\"; fi"
+done`
+			args := append(slices.Clone(sh.cmd[1:]), "-c", check, rc)
+			out, err := exec.Command(path, args...).CombinedOutput()
+			if err != nil {
+				t.Fatalf("%s failed: %v\n%s", sh.name, err, out)
+			}
+			if !strings.Contains(string(out), "ls -X") {
+				t.Errorf("alias not restored under %s: %q", sh.name, out)
+			}
+			if strings.Contains(string(out), "leftover") {
+				t.Errorf("wrapper definitions left under %s:\n%s", sh.name, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+//go:build dev
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kluzzebass/reflag/internal/scaffold"
+)
+
+// devCommands holds reflag dev, which only builds with -tags dev so the
+// scaffolder stays out of release binaries
+var devCommands = []*command{
+	{
+		name:    "dev",
+		args:    "new-translator SOURCE2TARGET | docs",
+		summary: "Scaffold a translator or regenerate the docs in a reflag checkout",
+		options: []option{{name: "--check", help: "With docs, only check that the README's generated blocks are up to date"}},
+		run:     func(g globalOptions, args []string) { runDev(args) },
+	},
+}
+
+// runDev handles reflag dev, tools for working on reflag itself:
+// new-translator NAME scaffolds a translator, and docs [--check] rewrites
+// the README blocks generated from translator metadata
+func runDev(args []string) {
+	valid := len(args) == 2 && args[0] == "new-translator" ||
+		len(args) >= 1 && args[0] == "docs" && (len(args) == 1 || len(args) == 2 && args[1] == "--check")
+	if !valid {
+		fmt.Fprintln(os.Stderr, "usage: reflag dev new-translator SOURCE2TARGET")
+		fmt.Fprintln(os.Stderr, "       reflag dev docs [--check]")
+		os.Exit(exitError)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	root, err := scaffold.FindRoot(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	if args[0] == "docs" {
+		runDocs(filepath.Join(root, "README.md"), len(args) == 2)
+		return
+	}
+
+	written, err := scaffold.NewTranslator(root, args[1])
+	for _, path := range written {
+		fmt.Println("wrote", path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Println("next: fill in the flag maps, metadata and tests, then run go test ./... and reflag dev docs")
+}
+
+// runDocs regenerates the README's generated blocks. With check it only
+// reports whether they are up to date, exiting with exitError if not.
+func runDocs(path string, check bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	updated, err := updateDocs(string(data))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s: %v\n", path, err)
+		os.Exit(exitError)
+	}
+	switch {
+	case updated == string(data):
+		fmt.Printf("%s is up to date\n", path)
+	case check:
+		fmt.Fprintf(os.Stderr, "%s is out of date; run go run -tags dev . dev docs\n", path)
+		os.Exit(exitError)
+	default:
+		if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitError)
+		}
+		fmt.Println("wrote", path)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// README blocks generated from translator metadata sit between these
+// markers; reflag dev docs rewrites them
+const (
+	docsBegin = "<!-- BEGIN GENERATED %s -->\n"
+	docsEnd   = "<!-- END GENERATED %s -->\n"
+)
+
+// installLabels names each package manager's platform in the install block
+var installLabels = map[string]string{
+	"brew":   "macOS",
+	"apt":    "Linux (Ubuntu/Debian)",
+	"dnf":    "Linux (Fedora)",
+	"pacman": "Arch Linux",
+	"cargo":  "Anywhere with a Rust toolchain",
+}
+
+// generatedDocs returns the generated README blocks by name
+func generatedDocs() map[string]string {
+	names := translator.List()
+	slices.Sort(names)
+
+	var list strings.Builder
+	pkgs := make(map[string][]string)
+	renames := make(map[string][]string)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		m := translator.Describe(t)
+		target := t.TargetTool()
+		if m.Homepage != "" {
+			target = "[" + target + "](" + m.Homepage + ")"
+		}
+		fmt.Fprintf(&list, "- `%s` → %s\n", t.SourceTool(), target)
+		for manager, pkg := range m.Install {
+			if !slices.Contains(pkgs[manager], pkg) {
+				pkgs[manager] = append(pkgs[manager], pkg)
+			}
+		}
+		for manager, bin := range m.Binaries {
+			renames[manager] = append(renames[manager], fmt.Sprintf("%s is installed as %s: ln -s /usr/bin/%s ~/.local/bin/%s", t.TargetTool(), bin, bin, t.TargetTool()))
+		}
+	}
+
+	var install strings.Builder
+	install.WriteString("```bash\n")
+	first := true
+	for _, manager := range translator.PackageManagers {
+		if len(pkgs[manager]) == 0 {
+			continue
+		}
+		if !first {
+			install.WriteString("\n")
+		}
+		first = false
+		slices.Sort(pkgs[manager])
+		fmt.Fprintf(&install, "# %s\n%s\n", installLabels[manager], translator.InstallCommand(manager, strings.Join(pkgs[manager], " ")))
+		for _, rename := range renames[manager] {
+			fmt.Fprintf(&install, "# %s\n", rename)
+		}
+	}
+	install.WriteString("```\n")
+
+	return map[string]string{"translators": list.String(), "install": install.String()}
+}
+
+// updateDocs replaces the generated blocks in a README. Every block must
+// be present exactly once.
+func updateDocs(readme string) (string, error) {
+	blocks := generatedDocs()
+	names := make([]string, 0, len(blocks))
+	for name := range blocks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		begin, end := fmt.Sprintf(docsBegin, name), fmt.Sprintf(docsEnd, name)
+		i := strings.Index(readme, begin)
+		j := strings.Index(readme, end)
+		if i < 0 || j < i || strings.Count(readme, begin) != 1 {
+			return "", fmt.Errorf("README needs one %q ... %q block", strings.TrimSpace(begin), strings.TrimSpace(end))
+		}
+		readme = readme[:i+len(begin)] + blocks[name] + readme[j:]
+	}
+	return readme, nil
+}
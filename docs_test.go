@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadmeUpToDate(t *testing.T) {
+	data, err := os.ReadFile("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := updateDocs(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != string(data) {
+		t.Error("README.md's generated blocks are out of date; run go run -tags dev . dev docs")
+	}
+	for _, want := range []string{"- `ls` → [eza](https://github.com/eza-community/eza)\n", "brew install bat doggo"} {
+		if !strings.Contains(updated, want) {
+			t.Errorf("generated docs missing %q", want)
+		}
+	}
+}
+
+func TestUpdateDocsMissingBlock(t *testing.T) {
+	if _, err := updateDocs("# reflag\n<!-- BEGIN GENERATED translators -->\n<!-- END GENERATED translators -->\n"); err == nil {
+		t.Error("updateDocs without an install block should fail")
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// doctor writes a health report: the config file, the shell setup and,
+// for the selected translators (every translator when verbose), whether
+// their tools are installed and the target is recent enough. It returns
+// the number of problems found; a target tool that isn't installed is not
+// one, since init skips its translator, but its install hint is shown.
+func doctor(w io.Writer, selection []string, verbose bool) int {
+	problems := 0
+	fmt.Fprintf(w, "reflag %s\n\n", version)
+
+	path := configPath()
+	_, err := loadConfig()
+	switch {
+	case err != nil:
+		fmt.Fprintf(w, "config:  error: %v\n", err)
+		problems++
+	case path == "":
+		fmt.Fprintln(w, "config:  none (no home directory)")
+	default:
+		if _, statErr := os.Stat(path); statErr != nil {
+			fmt.Fprintf(w, "config:  %s (not found, using defaults)\n", path)
+		} else {
+			fmt.Fprintf(w, "config:  %s\n", path)
+		}
+	}
+
+	fmt.Fprintf(w, "shell:   %s\n", shellSetup(filepath.Base(os.Getenv("SHELL"))))
+	if disabledValue(os.Getenv(disableEnv), time.Now()) {
+		fmt.Fprintf(w, "note:    %s is set; every translator is switched off\n", disableEnv)
+	}
+	fmt.Fprintln(w)
+
+	selected := selectTranslators(selection)
+	names := translator.List()
+	slices.Sort(names)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TRANSLATOR\tSOURCE\tTARGET\tVERSION\tSTATUS")
+	var missing []string
+	for _, name := range names {
+		t := translator.GetByName(name)
+		m := translator.Describe(t)
+		target, targetErr := exec.LookPath(t.TargetTool())
+		status := "active"
+		v := "-"
+		switch {
+		case !slices.Contains(selected, name):
+			status = "not selected"
+		case targetErr != nil:
+			status = "target not installed"
+			missing = append(missing, name)
+		default:
+			if v = toolVersion(target); v == "" {
+				v = "?"
+			} else if m.MinVersion != "" && translator.CompareVersions(v, m.MinVersion) < 0 {
+				status = "needs " + t.TargetTool() + " " + m.MinVersion
+				problems++
+			} else if translatorDisabled(name) {
+				status = "switched off"
+			}
+		}
+		if !verbose && status == "not selected" {
+			continue
+		}
+		source := toolLocation(t.SourceTool())
+		if f := translator.ResolveMode(t, ""); f != "" {
+			source += " (" + f + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", name, source, toolLocation(t.TargetTool()), v, status)
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	if len(missing) > 0 {
+		fmt.Fprintf(w, "%d selected translator(s) skipped by init until their target is installed:\n", len(missing))
+		// One hint per target, e.g. moor for both less2moor and more2moor
+		var targets []string
+		for _, name := range missing {
+			t := translator.GetByName(name)
+			if slices.Contains(targets, t.TargetTool()) {
+				continue
+			}
+			targets = append(targets, t.TargetTool())
+			fmt.Fprintf(w, "  %s: %s\n", t.TargetTool(), installHint(t.TargetTool(), translator.Describe(t)))
+		}
+		fmt.Fprintln(w)
+	}
+	if problems == 0 {
+		fmt.Fprintln(w, "No problems found")
+	} else {
+		fmt.Fprintf(w, "%d problem(s) found\n", problems)
+	}
+	return problems
+}
+
+// versionRe finds a dotted version number in --version output
+var versionRe = regexp.MustCompile(`\d+(?:\.\d+)+[a-z]?`)
+
+// toolVersion returns the version the tool at path reports through
+// --version or -V, or "" if it reports none
+func toolVersion(path string) string {
+	for _, arg := range []string{"--version", "-V"} {
+		out, err := runHelp(path, arg)
+		if err != nil {
+			continue
+		}
+		if v := versionRe.FindString(out); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// installHint returns how to install target: the command for the first
+// package manager on PATH that has it, else its homepage. Where the package
+// installs target under another name (see Metadata.Binaries), the hint says
+// how to link it, or only that when it is already installed.
+func installHint(target string, m translator.Metadata) string {
+	for _, manager := range translator.PackageManagers {
+		if bin, ok := m.Binaries[manager]; ok {
+			if _, err := exec.LookPath(bin); err == nil {
+				return fmt.Sprintf("%s is installed as %s; %s", target, bin, linkHint(target, bin))
+			}
+		}
+	}
+	for _, manager := range translator.PackageManagers {
+		pkg, ok := m.Install[manager]
+		if !ok {
+			continue
+		}
+		if _, err := exec.LookPath(manager); err != nil {
+			continue
+		}
+		hint := translator.InstallCommand(manager, pkg)
+		if bin, ok := m.Binaries[manager]; ok {
+			hint += fmt.Sprintf(", which installs it as %s; then %s", bin, linkHint(target, bin))
+		}
+		return hint
+	}
+	if m.Homepage != "" {
+		return "see " + m.Homepage
+	}
+	return "no install hint"
+}
+
+// linkHint says how to make bin available under the target's name
+func linkHint(target, bin string) string {
+	return fmt.Sprintf(`link it: ln -s "$(command -v %s)" ~/.local/bin/%s (or alias %s=%s)`, bin, target, target, bin)
+}
+
+// toolLocation returns the path tool resolves to, or notes it is missing
+func toolLocation(tool string) string {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return tool + " (missing)"
+	}
+	return path
+}
+
+// shellSetup describes how shell loads reflag: through the block reflag
+// install manages, another reflag init line, or not at all
+func shellSetup(shell string) string {
+	path, err := rcFile(shell)
+	if err != nil {
+		return fmt.Sprintf("%s (reflag install does not support it; add reflag init to its startup file)", shell)
+	}
+	content, err := readRC(path)
+	if err != nil {
+		return fmt.Sprintf("%s (error: %v)", shell, err)
+	}
+	if _, _, ok := findBlock(content); ok {
+		return fmt.Sprintf("%s, loaded by the reflag install block in %s", shell, path)
+	}
+	if strings.Contains(content, "reflag --init") || strings.Contains(content, "reflag init") {
+		return fmt.Sprintf("%s, loaded by %s", shell, path)
+	}
+	return fmt.Sprintf("%s, not set up in %s (run reflag install)", shell, path)
+}
+
+// runDoctor implements doctor [selection...] and exits with exitError when
+// it finds problems. With -v it lists unselected translators too.
+func runDoctor(g globalOptions, args []string) {
+	if doctor(os.Stdout, args, g.verbose) > 0 {
+		os.Exit(exitError)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+func TestInstallHint(t *testing.T) {
+	fd := translator.Describe(translator.GetByName("find2fd"))
+	tests := []struct {
+		name string
+		bin  []string
+		want string
+	}{
+		{"apt renames", []string{"apt"}, `sudo apt install fd-find, which installs it as fdfind; then link it: ln -s "$(command -v fdfind)" ~/.local/bin/fd`},
+		{"already installed", []string{"apt", "fdfind"}, "fd is installed as fdfind; link it:"},
+		{"pacman", []string{"pacman"}, "sudo pacman -S fd"},
+		{"no manager", nil, "see https://github.com/sharkdp/fd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("PATH", fakeBin(t, tt.bin...))
+			if got := installHint("fd", fd); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("installHint = %q, want it to start with %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoctorHintPerTarget(t *testing.T) {
+	t.Setenv("PATH", fakeBin(t, "less", "more"))
+	var buf bytes.Buffer
+	doctor(&buf, []string{"less2moor", "more2moor"}, false)
+	out := buf.String()
+	if !strings.Contains(out, "2 selected translator(s) skipped") {
+		t.Errorf("expected both translators counted:\n%s", out)
+	}
+	if n := strings.Count(out, "\n  moor: "); n != 1 {
+		t.Errorf("moor hint shown %d times, want once:\n%s", n, out)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// printExplain describes what reflag would do with args under the rules in
+// c instead of doing it: whether translation is switched off, which routing
+// rule fires, the fidelity grade of each flag that doesn't translate exactly,
+// and the resulting command for where the output goes
+func printExplain(w io.Writer, c *config, t translator.Translator, args []string, mode string, minimum translator.Fidelity, pipe string, ctx routeContext) {
+	source := t.SourceTool()
+	fmt.Fprintf(w, "command:  %s\n", joinQuoted(source, args))
+	fmt.Fprintf(w, "via:      %s\n", t.Name())
+	if _, ok := t.(translator.Flavored); ok || mode != "" {
+		fmt.Fprintf(w, "mode:     %s\n", describeMode(t, mode))
+	}
+
+	grade, mappings := translator.Grade(t, args, mode)
+	fmt.Fprintf(w, "fidelity: %s\n", grade)
+	if len(mappings) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, m := range mappings {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", m.Flag, m.Fidelity, m.Note)
+		}
+		tw.Flush()
+	}
+
+	switch r := c.route(source, args, ctx); {
+	case translatorDisabled(t.Name()):
+		fmt.Fprintf(w, "result:   %s (translation is switched off)\n", joinQuoted(source, args))
+	case r != nil:
+		fmt.Fprintf(w, "rule:     %s (%s)\n", r.text, r.where)
+		fmt.Fprintf(w, "result:   %s (routed by rule)\n", joinQuoted(source, args))
+	case grade < minimum:
+		fmt.Fprintf(w, "result:   %s (below minimum fidelity %s)\n", joinQuoted(source, args), minimum)
+	default:
+		result := ""
+		if out, ok := translateFor(t, args, mode, pipe, ctx.tty); ok {
+			result = joinQuoted(t.TargetTool(), out)
+		} else {
+			result = joinQuoted(source, args)
+		}
+		if !ctx.tty && pipe != pipeAlways {
+			result += fmt.Sprintf(" (output is not a terminal, pipe policy %s)", pipe)
+		}
+		fmt.Fprintf(w, "result:   %s\n", result)
+	}
+}
+
+// describeMode names the mode t translates with and where it came from:
+// --mode, the installed source tool, or the translator's own default
+func describeMode(t translator.Translator, mode string) string {
+	resolved := translator.ResolveMode(t, mode)
+	switch {
+	case mode != "":
+		return resolved + " (--mode)"
+	case resolved != "":
+		return resolved + " (detected from " + t.SourceTool() + ")"
+	}
+	return "default"
+}
+
+// joinQuoted joins a command and its arguments for display
+func joinQuoted(cmd string, args []string) string {
+	parts := []string{cmd}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
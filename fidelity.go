@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// minFidelityEnv overrides the config file's min-fidelity setting
+const minFidelityEnv = "REFLAG_MIN_FIDELITY"
+
+// minFidelity returns the lowest translation grade to emit, from
+// $REFLAG_MIN_FIDELITY or the config file. The default accepts everything.
+func minFidelity() translator.Fidelity {
+	if v := os.Getenv(minFidelityEnv); v != "" {
+		f, err := translator.ParseFidelity(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", minFidelityEnv, err)
+		}
+		return f
+	}
+	return userConfig().minFidelity
+}
+
+// belowMinimum reports whether translating args with t grades below minimum,
+// in which case the original command should run instead
+func belowMinimum(t translator.Translator, args []string, mode string, minimum translator.Fidelity) bool {
+	if minimum == translator.Lossy {
+		return false
+	}
+	grade, _ := translator.Grade(t, args, mode)
+	return grade < minimum
+}
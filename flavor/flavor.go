@@ -0,0 +1,162 @@
+// Package flavor tells which implementation of a Unix tool is installed:
+// GNU (coreutils, findutils, grep, procps), BSD (macOS and the BSDs) or
+// busybox. It runs the tool's --version once per binary and caches the
+// answer, keyed by the binary's path and modification time.
+package flavor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The flavors Detect reports
+const (
+	GNU     = "gnu"
+	BSD     = "bsd"
+	Busybox = "busybox"
+)
+
+// probeTimeout bounds each probe of a tool
+const probeTimeout = 2 * time.Second
+
+// Classify returns the flavor a tool's --version or -V output shows, or ""
+// if it doesn't tell. failed reports whether the tool exited with an error,
+// which is how BSD tools answer an option they don't know.
+func Classify(output string, failed bool) string {
+	switch {
+	case strings.Contains(output, "BusyBox"):
+		return Busybox
+	// BSD grep calls itself "BSD grep, GNU compatible"
+	case strings.Contains(output, "BSD"):
+		return BSD
+	case strings.Contains(output, "GNU"), strings.Contains(output, "procps"), strings.Contains(output, "coreutils"):
+		return GNU
+	}
+	lower := strings.ToLower(output)
+	if failed && (strings.Contains(lower, "illegal option") || strings.Contains(lower, "unrecognized option") ||
+		strings.Contains(lower, "invalid option") || strings.Contains(lower, "usage:")) {
+		return BSD
+	}
+	return ""
+}
+
+// probe runs the tool at path with --version, then -V, and classifies the
+// first answer that tells. A tool that is a link to busybox isn't run.
+func probe(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil && filepath.Base(real) == "busybox" {
+		return Busybox
+	}
+	for _, arg := range []string{"--version", "-V"} {
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		cmd := exec.CommandContext(ctx, path, arg)
+		cmd.Env = append(os.Environ(), "LC_ALL=C")
+		out, err := cmd.CombinedOutput()
+		timedOut := ctx.Err() != nil
+		cancel()
+		if timedOut {
+			return ""
+		}
+		if f := Classify(string(out), err != nil); f != "" {
+			return f
+		}
+	}
+	return ""
+}
+
+// entry is a cached answer for one binary
+type entry struct {
+	ModTime int64  `json:"mtime"` // UnixNano
+	Size    int64  `json:"size"`
+	Flavor  string `json:"flavor"`
+}
+
+var (
+	mu     sync.Mutex
+	loaded bool
+	cache  map[string]entry
+)
+
+// cachePath returns the cache file, reflag/flavor.json under the user's
+// cache directory
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "reflag", "flavor.json"), nil
+}
+
+// load reads the cache file once; a missing or broken file is an empty cache
+func load() {
+	if loaded {
+		return
+	}
+	loaded = true
+	cache = make(map[string]entry)
+	if path, err := cachePath(); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			json.Unmarshal(data, &cache)
+		}
+	}
+}
+
+// save writes the cache file through a temporary file, so concurrent
+// reflag processes never see half of it. Failing to save only costs a
+// probe next time.
+func save() {
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "flavor-*.json")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), path) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Detect returns the flavor of tool as found on PATH, or "" if it isn't
+// installed or doesn't tell. The answer is cached until the binary changes.
+func Detect(tool string) string {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	load()
+	if e, ok := cache[path]; ok && e.ModTime == info.ModTime().UnixNano() && e.Size == info.Size() {
+		return e.Flavor
+	}
+	f := probe(path)
+	cache[path] = entry{ModTime: info.ModTime().UnixNano(), Size: info.Size(), Flavor: f}
+	save()
+	return f
+}
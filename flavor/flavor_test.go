@@ -0,0 +1,118 @@
+package flavor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		failed bool
+		want   string
+	}{
+		{"GNU ls", "ls (GNU coreutils) 9.1\nCopyright (C) 2022 Free Software Foundation, Inc.\n", false, GNU},
+		{"GNU grep", "grep (GNU grep) 3.8\n", false, GNU},
+		{"GNU find", "find (GNU findutils) 4.9.0\n", false, GNU},
+		{"procps ps", "ps from procps-ng 4.0.2\n", false, GNU},
+		{"BSD grep", "grep (BSD grep, GNU compatible) 2.6.0-FreeBSD\n", false, BSD},
+		{"macOS ls", "ls: unrecognized option `--version'\nusage: ls [-@ABCFGHILOPRSTUWabcdefghiklmnopqrstuvwxy1%,] [--color=when] [-D format] [file ...]\n", true, BSD},
+		{"macOS find", "find: illegal option -- -\nusage: find [-H | -L | -P] [-EXdsx] [-f path] path ... [expression]\n", true, BSD},
+		{"busybox", "BusyBox v1.36.1 (2023-07-27 17:12:24 UTC) multi-call binary.\n\nUsage: ls [-1AaCxdLHRFplinshrSXvctu] [-w WIDTH] [FILE]...\n", true, Busybox},
+		{"unknown", "something 1.0\n", false, ""},
+		{"usage without failing", "usage: tool\n", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.output, tt.failed); got != tt.want {
+				t.Errorf("Classify(%q, %v) = %q, want %q", tt.output, tt.failed, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTool writes a script named tool into dir that logs each run to
+// dir/runs and prints output
+func writeTool(t *testing.T, dir, tool, output string) {
+	t.Helper()
+	script := "#!/bin/sh\necho run >> \"" + filepath.Join(dir, "runs") + "\"\nprintf '%s\\n' '" + output + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, tool), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runs returns how often the tools in dir have run
+func runs(t *testing.T, dir string) int {
+	data, err := os.ReadFile(filepath.Join(dir, "runs"))
+	if os.IsNotExist(err) {
+		return 0
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "run")
+}
+
+// reset forgets what Detect has read, as a new process would
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	loaded = false
+	cache = nil
+}
+
+func TestDetect(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	reset()
+	t.Cleanup(reset)
+
+	writeTool(t, bin, "ls", "ls (GNU coreutils) 9.1")
+	if got := Detect("ls"); got != GNU {
+		t.Fatalf("Detect(ls) = %q, want %q", got, GNU)
+	}
+	if got := Detect("ls"); got != GNU || runs(t, bin) != 1 {
+		t.Errorf("second Detect(ls) = %q after %d runs, want %q from the cache after 1", got, runs(t, bin), GNU)
+	}
+
+	reset()
+	if got := Detect("ls"); got != GNU || runs(t, bin) != 1 {
+		t.Errorf("Detect(ls) in a new process = %q after %d runs, want %q from the cache file after 1", got, runs(t, bin), GNU)
+	}
+
+	// Replacing the binary invalidates its entry
+	writeTool(t, bin, "ls", "BusyBox v1.36.1 (2023-07-27 17:12:24 UTC) multi-call binary.")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(bin, "ls"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := Detect("ls"); got != Busybox || runs(t, bin) != 2 {
+		t.Errorf("Detect(ls) after replacing it = %q after %d runs, want %q after 2", got, runs(t, bin), Busybox)
+	}
+
+	if got := Detect("nosuchtool"); got != "" {
+		t.Errorf("Detect(nosuchtool) = %q, want \"\"", got)
+	}
+}
+
+func TestDetectBusyboxLink(t *testing.T) {
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	reset()
+	t.Cleanup(reset)
+
+	writeTool(t, bin, "busybox", "not run")
+	if err := os.Symlink("busybox", filepath.Join(bin, "find")); err != nil {
+		t.Fatal(err)
+	}
+	if got := Detect("find"); got != Busybox || runs(t, bin) != 0 {
+		t.Errorf("Detect(find) = %q after %d runs, want %q without running it", got, runs(t, bin), Busybox)
+	}
+}
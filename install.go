@@ -0,0 +1,264 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Markers around the block reflag install manages in a shell rc file
+const (
+	blockBegin = "# >>> reflag >>>"
+	blockEnd   = "# <<< reflag <<<"
+)
+
+// rcFile returns the startup file reflag install edits for shell
+func rcFile(shell string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if dir := os.Getenv("ZDOTDIR"); dir != "" {
+			return filepath.Join(dir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		config := os.Getenv("XDG_CONFIG_HOME")
+		if config == "" {
+			config = filepath.Join(home, ".config")
+		}
+		return filepath.Join(config, "fish", "config.fish"), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", shell)
+}
+
+// installBlock returns the marked block that loads reflag in shell.
+// initArgs are passed on to --init (e.g. +dig2doggo -ls2eza).
+func installBlock(shell string, initArgs []string) string {
+	init := "reflag --init " + shell
+	for _, arg := range initArgs {
+		init += " " + shellQuote(arg)
+	}
+
+	line := `eval "$(` + init + `)"`
+	if shell == "fish" {
+		line = init + " | source"
+	}
+	return blockBegin + "\n" +
+		"# Managed by 'reflag install'; remove with 'reflag uninstall'\n" +
+		line + "\n" +
+		blockEnd + "\n"
+}
+
+// findBlock returns the byte range of the managed block in content,
+// including its trailing newline, or ok=false if there is none
+func findBlock(content string) (start, end int, ok bool) {
+	start = strings.Index(content, blockBegin+"\n")
+	if start < 0 {
+		return 0, 0, false
+	}
+	rel := strings.Index(content[start:], blockEnd)
+	if rel < 0 {
+		return 0, 0, false
+	}
+	end = start + rel + len(blockEnd)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+	return start, end, true
+}
+
+// withBlock returns content with block added, or replacing an existing block
+func withBlock(content, block string) string {
+	if start, end, ok := findBlock(content); ok {
+		return content[:start] + block + content[end:]
+	}
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	return content + block
+}
+
+// withoutBlock returns content with the managed block removed, along with
+// the blank line withBlock put in front of it
+func withoutBlock(content string) (string, bool) {
+	start, end, ok := findBlock(content)
+	if !ok {
+		return content, false
+	}
+	if start >= 2 && content[start-2:start] == "\n\n" {
+		start--
+	}
+	return content[:start] + content[end:], true
+}
+
+// installRC adds or updates the managed block in path
+func installRC(w io.Writer, path, block string, dryRun bool) error {
+	old, err := readRC(path)
+	if err != nil {
+		return err
+	}
+
+	updated := withBlock(old, block)
+	if updated == old {
+		fmt.Fprintf(w, "reflag is already installed in %s\n", path)
+		return nil
+	}
+	if _, _, ok := findBlock(old); ok {
+		fmt.Fprintf(w, "updating the reflag block in %s\n", path)
+	}
+	return writeRC(w, path, old, updated, dryRun)
+}
+
+// uninstallRC removes the managed block from path
+func uninstallRC(w io.Writer, path string, dryRun bool) error {
+	old, err := readRC(path)
+	if err != nil {
+		return err
+	}
+
+	updated, ok := withoutBlock(old)
+	if !ok {
+		fmt.Fprintf(w, "reflag is not installed in %s\n", path)
+		return nil
+	}
+	return writeRC(w, path, old, updated, dryRun)
+}
+
+// readRC returns the contents of path, or "" if it doesn't exist yet
+func readRC(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	return string(data), err
+}
+
+// writeRC replaces path's contents, keeping a backup of the old file.
+// With dryRun it only prints the diff.
+func writeRC(w io.Writer, path, old, updated string, dryRun bool) error {
+	if dryRun {
+		fmt.Fprint(w, unifiedDiff(path, old, updated))
+		return nil
+	}
+
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+		backup := path + ".reflag.bak"
+		if err := os.WriteFile(backup, []byte(old), mode); err != nil {
+			return fmt.Errorf("backing up %s: %w", path, err)
+		}
+		fmt.Fprintf(w, "backed up %s to %s\n", path, backup)
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(updated), mode); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "updated %s\n", path)
+	return nil
+}
+
+// unifiedDiff renders the change from old to updated as a single-hunk
+// unified diff. The block is always one contiguous change, so trimming the
+// common prefix and suffix is all the diffing needed.
+func unifiedDiff(path, old, updated string) string {
+	a := splitLines(old)
+	b := splitLines(updated)
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	const context = 3
+	start := max(prefix-context, 0)
+	aEnd := min(len(a)-suffix+context, len(a))
+	bEnd := min(len(b)-suffix+context, len(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", path, path)
+	fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(start, aEnd-start), hunkRange(start, bEnd-start))
+	for _, line := range a[start:prefix] {
+		sb.WriteString(" " + line + "\n")
+	}
+	for _, line := range a[prefix : len(a)-suffix] {
+		sb.WriteString("-" + line + "\n")
+	}
+	for _, line := range b[prefix : len(b)-suffix] {
+		sb.WriteString("+" + line + "\n")
+	}
+	for _, line := range a[len(a)-suffix : aEnd] {
+		sb.WriteString(" " + line + "\n")
+	}
+	return sb.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// runInstall implements reflag install|uninstall [--shell=SHELL] [--dry-run] [init args...]
+// The shell defaults to the basename of $SHELL.
+func runInstall(command string, args []string) {
+	shell := filepath.Base(os.Getenv("SHELL"))
+	dryRun := false
+	var initArgs []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--dry-run":
+			dryRun = true
+		case arg == "--shell" && i+1 < len(args):
+			shell = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--shell="):
+			shell = strings.TrimPrefix(arg, "--shell=")
+		case strings.HasPrefix(arg, "--"):
+			fmt.Fprintf(os.Stderr, "error: unknown %s option %q\n", command, arg)
+			os.Exit(exitError)
+		default:
+			initArgs = append(initArgs, arg)
+		}
+	}
+
+	path, err := rcFile(shell)
+	if err == nil {
+		if command == "uninstall" {
+			err = uninstallRC(os.Stdout, path, dryRun)
+		} else {
+			err = installRC(os.Stdout, path, installBlock(shell, initArgs), dryRun)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
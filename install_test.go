@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallBlock(t *testing.T) {
+	tests := []struct {
+		shell    string
+		initArgs []string
+		want     string
+	}{
+		{"bash", nil, `eval "$(reflag --init bash)"`},
+		{"zsh", []string{"+dig2doggo", "-ls2eza"}, `eval "$(reflag --init zsh +dig2doggo -ls2eza)"`},
+		{"fish", nil, "reflag --init fish | source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			block := installBlock(tt.shell, tt.initArgs)
+			lines := strings.Split(strings.TrimSuffix(block, "\n"), "\n")
+			if lines[0] != blockBegin || lines[len(lines)-1] != blockEnd {
+				t.Errorf("block not marked:\n%s", block)
+			}
+			if lines[len(lines)-2] != tt.want {
+				t.Errorf("init line = %q, want %q", lines[len(lines)-2], tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBlock(t *testing.T) {
+	block := installBlock("bash", nil)
+	newer := installBlock("bash", []string{"+dig2doggo"})
+
+	tests := []struct {
+		name    string
+		content string
+		block   string
+		want    string
+	}{
+		{"empty file", "", block, block},
+		{"appends after blank line", "alias ll='ls -l'\n", block, "alias ll='ls -l'\n\n" + block},
+		{"missing final newline", "export A=1", block, "export A=1\n\n" + block},
+		{"already installed", "export A=1\n\n" + block, block, "export A=1\n\n" + block},
+		{"replaces in place", "A=1\n\n" + block + "B=2\n", newer, "A=1\n\n" + newer + "B=2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withBlock(tt.content, tt.block); got != tt.want {
+				t.Errorf("withBlock() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutBlock(t *testing.T) {
+	block := installBlock("zsh", nil)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		found   bool
+	}{
+		{"not installed", "export A=1\n", "export A=1\n", false},
+		{"only block", block, "", true},
+		{"undoes append", "export A=1\n\n" + block, "export A=1\n", true},
+		{"keeps surrounding lines", "A=1\n\n" + block + "B=2\n", "A=1\nB=2\n", true},
+		{"unterminated marker", blockBegin + "\nfoo\n", blockBegin + "\nfoo\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := withoutBlock(tt.content)
+			if got != tt.want || found != tt.found {
+				t.Errorf("withoutBlock() = %q, %v, want %q, %v", got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestInstallRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	original := "# my config\nalias ll='ls -l'\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	block := installBlock("bash", nil)
+
+	var out bytes.Buffer
+	if err := installRC(&out, path, block, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Fatal("--dry-run modified the file")
+	}
+	wantDiff := "--- " + path + "\n+++ " + path + "\n" +
+		"@@ -1,2 +1,7 @@\n" +
+		" # my config\n" +
+		" alias ll='ls -l'\n" +
+		"+\n" +
+		"+" + blockBegin + "\n" +
+		"+# Managed by 'reflag install'; remove with 'reflag uninstall'\n" +
+		"+eval \"$(reflag --init bash)\"\n" +
+		"+" + blockEnd + "\n"
+	if out.String() != wantDiff {
+		t.Errorf("dry run diff =\n%s\nwant:\n%s", out.String(), wantDiff)
+	}
+
+	if err := installRC(&out, path, block, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path + ".reflag.bak"); string(data) != original {
+		t.Errorf("backup = %q, want %q", data, original)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	out.Reset()
+	if err := installRC(&out, path, block, false); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "already installed") {
+		t.Errorf("second install output = %q, want already installed", out.String())
+	}
+
+	if err := uninstallRC(&out, path, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("after uninstall = %q, want %q", data, original)
+	}
+}
+
+func TestInstallCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fish", "config.fish")
+	block := installBlock("fish", nil)
+
+	var out bytes.Buffer
+	if err := installRC(&out, path, block, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != block {
+		t.Errorf("new file = %q, want %q", data, block)
+	}
+	if _, err := os.Stat(path + ".reflag.bak"); err == nil {
+		t.Error("backup written for a file that didn't exist")
+	}
+}
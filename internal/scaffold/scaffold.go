@@ -0,0 +1,417 @@
+// Package scaffold generates the boilerplate for new translators and keeps
+// the translator/all import list in sync with the translator directory.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// nameRe matches translator names: the source tool, a 2, and the target tool
+var nameRe = regexp.MustCompile(`^([a-z][a-z0-9]*?)2([a-z][a-z0-9]*)$`)
+
+// readmeAnchor is the README heading new translator sections are inserted before
+const readmeAnchor = "## Adding New Translators"
+
+// SplitName splits a translator name such as tail2moor into its source and
+// target tools. The name splits at the first 2.
+func SplitName(name string) (source, target string, err error) {
+	m := nameRe.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", fmt.Errorf("bad translator name %q (expected SOURCE2TARGET, e.g. tail2moor)", name)
+	}
+	return m[1], m[2], nil
+}
+
+// Module is the module path of the reflag source tree
+const Module = "github.com/kluzzebass/reflag"
+
+// errNotReflag is returned for directories outside the reflag source tree
+var errNotReflag = errors.New("not inside the reflag source tree")
+
+// FindRoot walks up from dir to the directory holding go.mod, and checks it
+// is reflag's, so nothing is written into some other Go project
+func FindRoot(dir string) (string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, checkRoot(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w (no go.mod found)", errNotReflag)
+		}
+		dir = parent
+	}
+}
+
+// checkRoot makes sure root is the reflag source tree: its module is
+// Module and it has the translator registry and translator/all
+func checkRoot(root string) error {
+	module, err := modulePath(root)
+	if err != nil {
+		return err
+	}
+	if module != Module {
+		return fmt.Errorf("%w (%s is module %s)", errNotReflag, root, module)
+	}
+	for _, path := range []string{"translator/registry.go", "translator/all"} {
+		if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+			return fmt.Errorf("%w (%s has no %s)", errNotReflag, root, path)
+		}
+	}
+	return nil
+}
+
+// modulePath reads the module path from root/go.mod
+func modulePath(root string) (string, error) {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("go.mod has no module line")
+}
+
+// Translators lists the translator packages under root/translator, sorted.
+// A translator package is a directory holding translator.go.
+func Translators(root string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "translator"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "all" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, "translator", e.Name(), "translator.go")); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+var allTemplate = template.Must(template.New("all").Parse(`// Code generated by go generate; DO NOT EDIT.
+
+package all
+
+import (
+{{- range .Names}}
+	_ "{{$.Module}}/translator/{{.}}"
+{{- end}}
+)
+`))
+
+// AllSource returns the contents of translator/all/all.go for the
+// translators under root
+func AllSource(root string) ([]byte, error) {
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+	names, err := Translators(root)
+	if err != nil {
+		return nil, err
+	}
+	return allSource(module, names)
+}
+
+// allSource returns the contents of translator/all/all.go importing names
+func allSource(module string, names []string) ([]byte, error) {
+	return execute(allTemplate, map[string]any{"Module": module, "Names": names})
+}
+
+// WriteAll regenerates translator/all/all.go
+func WriteAll(root string) error {
+	src, err := AllSource(root)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(root, "translator", "all", "all.go"), src, 0o644)
+}
+
+// NewTranslator scaffolds translator/NAME with a translator, a table-driven
+// test file and a README section, then regenerates translator/all. Every
+// file is generated before the first is written. It returns the paths it
+// wrote.
+func NewTranslator(root, name string) ([]string, error) {
+	source, target, err := SplitName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRoot(root); err != nil {
+		return nil, err
+	}
+	module := Module
+	dir := filepath.Join(root, "translator", name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%s already exists", dir)
+	}
+
+	data := map[string]any{"Name": name, "Source": source, "Target": target, "Module": module}
+	files := []struct {
+		name string
+		tmpl *template.Template
+	}{
+		{"translator.go", translatorTemplate},
+		{"translator_test.go", testTemplate},
+	}
+	var srcs [][]byte
+	for _, f := range files {
+		src, err := execute(f.tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		srcs = append(srcs, src)
+	}
+
+	readme := filepath.Join(root, "README.md")
+	doc, err := os.ReadFile(readme)
+	if err != nil {
+		return nil, err
+	}
+	section, err := readmeSection(data)
+	if err != nil {
+		return nil, err
+	}
+	doc = insertSection(doc, section)
+
+	names, err := Translators(root)
+	if err != nil {
+		return nil, err
+	}
+	names = append(names, name)
+	slices.Sort(names)
+	all, err := allSource(module, names)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var written []string
+	for i, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, srcs[i], 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	if err := os.WriteFile(readme, doc, 0o644); err != nil {
+		return written, err
+	}
+	written = append(written, readme)
+	allPath := filepath.Join(root, "translator", "all", "all.go")
+	if err := os.WriteFile(allPath, all, 0o644); err != nil {
+		return written, err
+	}
+	return append(written, allPath), nil
+}
+
+// insertSection adds section to the README before the Adding New
+// Translators heading, or at the end if the heading is missing
+func insertSection(doc []byte, section string) []byte {
+	i := bytes.Index(doc, []byte("\n"+readmeAnchor+"\n"))
+	if i < 0 {
+		if len(doc) > 0 && !bytes.HasSuffix(doc, []byte("\n")) {
+			doc = append(doc, '\n')
+		}
+		return append(doc, "\n"+section...)
+	}
+	var b bytes.Buffer
+	b.Write(doc[:i+1])
+	b.WriteString(section)
+	b.WriteString("\n")
+	b.Write(doc[i+1:])
+	return b.Bytes()
+}
+
+// execute runs a Go source template and formats the result
+func execute(t *template.Template, data any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting %s: %w", t.Name(), err)
+	}
+	return src, nil
+}
+
+func readmeSection(data map[string]any) (string, error) {
+	var b strings.Builder
+	if err := readmeTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var translatorTemplate = template.Must(template.New("translator.go").Parse(`package {{.Name}}
+
+import (
+	"strings"
+
+	"{{.Module}}/translator"
+)
+
+func init() {
+	translator.Register(&Translator{})
+}
+
+// Translator implements the {{.Source}} to {{.Target}} flag translation
+type Translator struct{}
+
+func (t *Translator) Name() string        { return "{{.Name}}" }
+func (t *Translator) SourceTool() string  { return "{{.Source}}" }
+func (t *Translator) TargetTool() string  { return "{{.Target}}" }
+func (t *Translator) IncludeInInit() bool { return true }
+
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Runs {{.Target}} instead of {{.Source}}",
+		Homepage:    "",
+		Tags:        []string{},
+		MinVersion:  "",
+		Install:     map[string]string{},
+	}
+}
+
+// Translate converts {{.Source}} arguments to {{.Target}} arguments
+func (t *Translator) Translate(args []string, mode string) []string {
+	return translateFlags(args)
+}
+
+// Simple 1:1 flag mappings from {{.Source}} to {{.Target}}
+// An empty slice drops the flag; unlisted flags pass through unchanged
+var flagMap = map[rune][]string{}
+
+// Long option mappings from {{.Source}} to {{.Target}}
+var longFlagMap = map[string][]string{}
+
+func translateFlags(args []string) []string {
+	result := []string{}
+	var operands []string
+
+	for i, arg := range args {
+		// Everything after -- is an operand
+		if arg == "--" {
+			operands = append(operands, args[i:]...)
+			break
+		}
+
+		// Long flags
+		if strings.HasPrefix(arg, "--") {
+			if mapped, ok := longFlagMap[arg]; ok {
+				result = append(result, mapped...)
+			} else {
+				result = append(result, arg)
+			}
+			continue
+		}
+
+		// Bundled short flags
+		if strings.HasPrefix(arg, "-") && len(arg) > 1 {
+			for _, flag := range arg[1:] {
+				if mapped, ok := flagMap[flag]; ok {
+					result = append(result, mapped...)
+				} else {
+					result = append(result, "-"+string(flag))
+				}
+			}
+			continue
+		}
+
+		operands = append(operands, arg)
+	}
+
+	return append(result, operands...)
+}
+`))
+
+var testTemplate = template.Must(template.New("translator_test.go").Parse(`package {{.Name}}
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranslateFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string
+	}{
+		{
+			name:     "no arguments",
+			input:    []string{},
+			expected: []string{},
+		},
+		{
+			name:     "operands pass through",
+			input:    []string{"file.txt"},
+			expected: []string{"file.txt"},
+		},
+		{
+			name:     "end of options",
+			input:    []string{"--", "-file"},
+			expected: []string{"--", "-file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := translateFlags(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("translateFlags(%v) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslatorInterface(t *testing.T) {
+	tr := &Translator{}
+
+	if tr.Name() != "{{.Name}}" {
+		t.Errorf("Name() = %q, want %q", tr.Name(), "{{.Name}}")
+	}
+	if tr.SourceTool() != "{{.Source}}" {
+		t.Errorf("SourceTool() = %q, want %q", tr.SourceTool(), "{{.Source}}")
+	}
+	if tr.TargetTool() != "{{.Target}}" {
+		t.Errorf("TargetTool() = %q, want %q", tr.TargetTool(), "{{.Target}}")
+	}
+}
+`))
+
+var readmeTemplate = template.Must(template.New("README").Parse("## {{.Name}} Translator\n" + `
+The {{.Name}} translator converts ` + "`{{.Source}}`" + ` flags to ` + "`{{.Target}}`" + ` equivalents.
+
+### Supported Flags
+
+| {{.Source}} flag | {{.Target}} equivalent | Description |
+|---------|----------------|-------------|
+
+### Examples
+
+` + "```bash\nreflag {{.Source}} {{.Target}} file.txt\n# Outputs: {{.Target}} file.txt\n```\n"))
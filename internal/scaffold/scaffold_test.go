@@ -0,0 +1,123 @@
+package scaffold
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		name           string
+		source, target string
+	}{
+		{"tail2moor", "tail", "moor"},
+		{"du2dust", "du", "dust"},
+		{"b2sum2xxh", "b", "sum2xxh"},
+		{"tail", "", ""},
+		{"2moor", "", ""},
+		{"tail2", "", ""},
+		{"Tail2Moor", "", ""},
+		{"tail-2-moor", "", ""},
+	}
+
+	for _, tt := range tests {
+		source, target, err := SplitName(tt.name)
+		if tt.source == "" {
+			if err == nil {
+				t.Errorf("SplitName(%q) should fail", tt.name)
+			}
+			continue
+		}
+		if err != nil || source != tt.source || target != tt.target {
+			t.Errorf("SplitName(%q) = %q, %q, %v; want %q, %q", tt.name, source, target, err, tt.source, tt.target)
+		}
+	}
+}
+
+func TestNewTranslator(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module github.com/kluzzebass/reflag\n\ngo 1.25\n")
+	write("README.md", "# reflag\n\n## ls2eza Translator\n\n## Adding New Translators\n\nSteps.\n")
+	write("translator/registry.go", "package translator\n")
+	write("translator/ls2eza/translator.go", "package ls2eza\n")
+	write("translator/all/all.go", "package all\n")
+
+	if _, err := NewTranslator(root, "tail2moor"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTranslator(root, "tail2moor"); err == nil {
+		t.Error("scaffolding an existing translator should fail")
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(filepath.Join(root, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	for path, want := range map[string]string{
+		"translator/tail2moor/translator.go":      `func (t *Translator) SourceTool() string  { return "tail" }`,
+		"translator/tail2moor/translator_test.go": `if tr.TargetTool() != "moor" {`,
+		"translator/all/all.go":                   "\t_ \"github.com/kluzzebass/reflag/translator/ls2eza\"\n\t_ \"github.com/kluzzebass/reflag/translator/tail2moor\"\n",
+	} {
+		if got := read(path); !strings.Contains(got, want) {
+			t.Errorf("%s missing %q:\n%s", path, want, got)
+		}
+	}
+
+	readme := read("README.md")
+	section := strings.Index(readme, "## tail2moor Translator\n")
+	if section < 0 || section > strings.Index(readme, "## Adding New Translators") {
+		t.Errorf("README section not inserted before Adding New Translators:\n%s", readme)
+	}
+}
+
+// TestNewTranslatorOtherModule checks nothing is written into a Go project
+// that isn't reflag
+func TestNewTranslatorOtherModule(t *testing.T) {
+	root := t.TempDir()
+	readme := "# other\n\n## Adding New Translators\n"
+	files := map[string]string{
+		"go.mod":                 "module example.com/other\n\ngo 1.25\n",
+		"README.md":              readme,
+		"translator/registry.go": "package translator\n",
+		"sub/x.go":               "package sub\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := FindRoot(filepath.Join(root, "sub")); err == nil {
+		t.Error("FindRoot should reject another module")
+	}
+	written, err := NewTranslator(root, "tail2moor")
+	if err == nil || len(written) > 0 {
+		t.Errorf("NewTranslator = %q, %v; want an error and nothing written", written, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "translator", "tail2moor")); err == nil {
+		t.Error("translator/tail2moor was created")
+	}
+	if got, _ := os.ReadFile(filepath.Join(root, "README.md")); string(got) != readme {
+		t.Errorf("README.md was changed:\n%s", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Package usage reads the options a tool's usage text documents, such as
+// busybox's --help, so tests can check a translator against them
+package usage
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// Option is an option documented in a usage text
+type Option struct {
+	Name string // "-w", "--color" or "-name"
+	Arg  string // placeholder for its value, e.g. "N"; "" if it takes none
+}
+
+// groupRe finds bracketed groups of option letters such as [-1AaC]
+var groupRe = regexp.MustCompile(`\[-([0-9A-Za-z]+)\]`)
+
+// Parse returns the options text documents: the first column of every
+// indented help line that starts with "-", split at commas (-L,-follow),
+// with its value placeholder, and the other letters of bracketed groups
+// like [-1AaC] on the Usage line, which show no placeholders. Optional
+// values (--color[=WHEN]) are dropped.
+func Parse(text string) []Option {
+	var synopsis, opts []Option
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "Usage:") {
+			for _, m := range groupRe.FindAllStringSubmatch(line, -1) {
+				for _, c := range m[1] {
+					synopsis = append(synopsis, Option{Name: "-" + string(c)})
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(line, "\t-") {
+			continue
+		}
+		column, _, _ := strings.Cut(line[1:], "\t")
+		name, arg, _ := strings.Cut(column, " ")
+		name, _, _ = strings.Cut(name, "[")
+		for _, n := range strings.Split(name, ",") {
+			opts = append(opts, Option{Name: n, Arg: arg})
+		}
+	}
+	for _, o := range synopsis {
+		if !slices.ContainsFunc(opts, func(b Option) bool { return b.Name == o.Name }) {
+			opts = append(opts, o)
+		}
+	}
+	return opts
+}
+
+// Args returns o as arguments, with value standing in for its placeholder
+func (o Option) Args(value string) []string {
+	if o.Arg == "" {
+		return []string{o.Name}
+	}
+	return []string{o.Name, value}
+}
+
+// Rejected returns the flags in mappings graded as options the source tool
+// doesn't have; see translator.Unsupported
+func Rejected(mappings []translator.Mapping) []string {
+	var flags []string
+	for _, m := range mappings {
+		if strings.Contains(m.Note, "has no such option") {
+			flags = append(flags, m.Flag)
+		}
+	}
+	return flags
+}
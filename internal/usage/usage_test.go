@@ -0,0 +1,24 @@
+package usage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	text := "Usage: ls [-1Aa] [-w WIDTH] [FILE]...\n\nList directory contents\n\n" +
+		"\t-1\tOne column output\n" +
+		"\t-L,-follow\tFollow symlinks\n" +
+		"\t-w N\tFormat N columns wide\n" +
+		"\t--color[={always,never,auto}]\n" +
+		"\t\t\tcontinued description\n" +
+		"\t( ACTIONS )\tGroup actions\n"
+	want := []Option{
+		{Name: "-1"}, {Name: "-L"}, {Name: "-follow"},
+		{Name: "-w", Arg: "N"}, {Name: "--color"},
+		{Name: "-A"}, {Name: "-a"},
+	}
+	if got := Parse(text); !slices.Equal(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
@@ -1,23 +1,26 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"sort"
+	"slices"
 	"strings"
 
+	"github.com/kluzzebass/reflag/cmdline"
+	"github.com/kluzzebass/reflag/flavor"
 	"github.com/kluzzebass/reflag/translator"
-	_ "github.com/kluzzebass/reflag/translator/bat2cat"   // Register bat2cat translator
-	_ "github.com/kluzzebass/reflag/translator/df2duf"    // Register df2duf translator
-	_ "github.com/kluzzebass/reflag/translator/dig2doggo" // Register dig2doggo translator
-	_ "github.com/kluzzebass/reflag/translator/du2dust"   // Register du2dust translator
-	_ "github.com/kluzzebass/reflag/translator/find2fd"   // Register find2fd translator
-	_ "github.com/kluzzebass/reflag/translator/grep2rg"   // Register grep2rg translator
-	_ "github.com/kluzzebass/reflag/translator/less2moor" // Register less2moor translator
-	_ "github.com/kluzzebass/reflag/translator/ls2eza"    // Register ls2eza translator
-	_ "github.com/kluzzebass/reflag/translator/more2moor" // Register more2moor translator
-	_ "github.com/kluzzebass/reflag/translator/ps2procs"     // Register ps2procs translator
-	_ "github.com/kluzzebass/reflag/translator/screen2tmux" // Register screen2tmux translator
+	_ "github.com/kluzzebass/reflag/translator/all" // Register every translator
+)
+
+// Exit codes. Shell wrappers fall back to the source tool on any failure,
+// but stay quiet when reflag deliberately refused to translate. Exit status 2
+// is left to the Go runtime, which uses it for an unrecovered panic.
+const (
+	exitError   = 1 // bad invocation or internal error
+	exitRefused = 3 // no translator, or translation refused
+	exitDiffers = 4 // compare: the source and target commands' results differ
 )
 
 // Version information - set via ldflags at build time
@@ -27,11 +30,9 @@ var (
 	date    = "unknown"
 )
 
+// shellQuote quotes s for POSIX shells so that eval sees exactly one word
 func shellQuote(s string) string {
-	if strings.ContainsAny(s, " \t\n\"'\\$`!") {
-		return "'" + strings.ReplaceAll(s, "'", "'\"'\"'") + "'"
-	}
-	return s
+	return cmdline.Quote(s, "sh")
 }
 
 func printVersion(name string) {
@@ -71,113 +72,54 @@ SOFTWARE.`
 	fmt.Println(licenseText)
 }
 
-// parseInitArgs parses --init arguments, returning shell type and add/remove lists
-// Shell can appear anywhere in args; defaults to "bash" if not specified
-// Arguments starting with + are added to defaults, - are removed from defaults
-func parseInitArgs(args []string) (shell string, add []string, remove []string) {
-	shell = "bash"
-	for _, arg := range args {
-		switch {
-		case arg == "bash" || arg == "zsh" || arg == "fish":
-			shell = arg
-		case strings.HasPrefix(arg, "+"):
-			add = append(add, strings.TrimPrefix(arg, "+"))
-		case strings.HasPrefix(arg, "-"):
-			remove = append(remove, strings.TrimPrefix(arg, "-"))
-		}
-	}
-	return
-}
-
-func printInit(shell string, add []string, remove []string) {
-	// Start with default translators
-	nameSet := make(map[string]bool)
-	for _, name := range translator.List() {
-		t := translator.GetByName(name)
-		if t != nil && t.IncludeInInit() {
-			nameSet[name] = true
-		}
-	}
-
-	// Remove specified translators
-	for _, name := range remove {
-		if translator.GetByName(name) != nil {
-			delete(nameSet, name)
-		} else {
-			fmt.Fprintf(os.Stderr, "warning: unknown translator %q\n", name)
-		}
-	}
-
-	// Add specified translators
-	for _, name := range add {
-		if translator.GetByName(name) != nil {
-			nameSet[name] = true
-		} else {
-			fmt.Fprintf(os.Stderr, "warning: unknown translator %q\n", name)
-		}
-	}
-
-	// Convert to sorted slice
-	var names []string
-	for name := range nameSet {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
-	switch shell {
-	case "fish":
-		fmt.Println("# reflag shell init - add to your ~/.config/fish/config.fish")
-		fmt.Println()
-		for _, name := range names {
-			t := translator.GetByName(name)
-			fmt.Printf("functions -e %s 2>/dev/null\n", t.SourceTool())
-			fmt.Printf("function %s\n", t.SourceTool())
-			fmt.Printf("    eval (reflag %s %s $argv)\n", t.SourceTool(), t.TargetTool())
-			fmt.Println("end")
-			fmt.Println()
-		}
-	default: // bash, zsh
-		fmt.Println("# reflag shell init - add to your ~/.bashrc or ~/.zshrc")
-		fmt.Println()
-		for _, name := range names {
-			t := translator.GetByName(name)
-			fmt.Printf("unalias %s 2>/dev/null\n", t.SourceTool())
-			fmt.Printf("%s() {\n", t.SourceTool())
-			fmt.Printf("    eval \"$(reflag %s %s \"$@\")\"\n", t.SourceTool(), t.TargetTool())
-			fmt.Println("}")
-			fmt.Println()
-		}
-	}
-}
-
 func printUsage() {
 	fmt.Println("reflag - translate command-line flags between tools")
 	fmt.Println()
 	fmt.Println("Quick setup:")
-	fmt.Println("  echo 'eval \"$(reflag --init)\"' >> ~/.bashrc")
-	fmt.Println("  echo 'eval \"$(reflag --init)\"' >> ~/.zshrc")
-	fmt.Println("  echo 'reflag --init fish | source' >> ~/.config/fish/config.fish")
+	fmt.Println("  reflag install --shell bash    # adds a block to ~/.bashrc")
+	fmt.Println("  reflag install --shell zsh     # adds a block to ~/.zshrc")
+	fmt.Println("  reflag install --shell fish    # adds a block to ~/.config/fish/config.fish")
 	fmt.Println()
 	fmt.Println("Usage:")
-	fmt.Println("  reflag [--mode=MODE] <source> <target> [flags...]")
-	fmt.Println("  reflag --list")
-	fmt.Println("  reflag --init [bash|zsh|fish] [+translator...] [-translator...]")
-	fmt.Println("  reflag --version")
-	fmt.Println("  reflag --license")
+	fmt.Println("  reflag [global options] <command> [options] [arguments]")
+	fmt.Println("  reflag [global options] [options] <source> <target> [flags...]   (translate)")
+	fmt.Println()
+	fmt.Println("Commands:")
+	writeCommands(os.Stdout)
+	fmt.Println()
+	fmt.Println("Run 'reflag <command> --help' for its options. The older forms --init, --deinit,")
+	fmt.Println("--list, --explain and --version still work; the shell code uses")
+	fmt.Println("  reflag --rewrite [--mode=MODE] [--translators=NAME,...] LINE")
+	fmt.Println("  reflag --wrap [--translators=NAME,...] PREFIX [args...]")
+	fmt.Println()
+	fmt.Println("Global options, anywhere before the source tool (--mode and --config also after it):")
+	writeOptions(os.Stdout, globalOptionList)
+	fmt.Println()
+	fmt.Println("Translator selection (init, deinit, list, doctor, install):")
+	fmt.Println("  name           Select exactly the named translators (e.g., ls2eza grep2rg)")
+	fmt.Println("  +term          Add to the defaults (e.g., +dig2doggo)")
+	fmt.Println("  -term          Remove from the defaults (e.g., -ls2eza)")
+	fmt.Println("                 A term is a translator name, a glob ('*2moor'), a tag")
+	fmt.Println("                 (pagers, search, system, ...), all or default")
 	fmt.Println()
-	fmt.Println("Options:")
-	fmt.Println("  --mode=MODE    Set dialect mode (e.g., bsd or gnu for ls2eza)")
-	fmt.Println("                 Auto-detects from OS if not specified")
+	fmt.Println("Runtime switches (set by off/on/pause through the reflag shell function):")
+	fmt.Println("  REFLAG_DISABLE=1          Disable all translators")
+	fmt.Println("  REFLAG_DISABLE_<NAME>=1   Disable one translator (e.g. REFLAG_DISABLE_LS2EZA)")
 	fmt.Println()
-	fmt.Println("Init modifiers:")
-	fmt.Println("  +translator    Add translator to defaults (e.g., +dig2doggo)")
-	fmt.Println("  -translator    Remove translator from defaults (e.g., -ls2eza)")
+	fmt.Println("Config file ($REFLAG_CONFIG, default ~/.config/reflag/config):")
+	fmt.Println("  translators = SELECTION   Selection applied before the command line's")
+	fmt.Println("  validate = POLICY         off, warn or drop (see --validate)")
+	fmt.Println("  min-fidelity = LEVEL      exact, approximate or lossy (see --min-fidelity)")
+	fmt.Println("  pipe = POLICY             always, tty-only or tty-or-compatible (see --pipe)")
+	fmt.Println("  pipe.NAME = POLICY        The pipe policy for one translator")
+	fmt.Println("  route = CONDITIONS        Run matching commands untranslated; conditions are")
+	fmt.Println("                            tool=NAME flag=FLAG arg=REGEX cwd=GLOB tty=yes|no")
 	fmt.Println()
 	fmt.Println("Available translators:")
 	translator.PrintTable(os.Stdout)
 }
 
-func runTranslator(t translator.Translator, args []string, mode string) {
+func runTranslator(t translator.Translator, args []string, mode, format, policy, pipe string, tty bool) {
 	// Handle version flag
 	for _, arg := range args {
 		if arg == "-V" || arg == "--version" {
@@ -186,7 +128,19 @@ func runTranslator(t translator.Translator, args []string, mode string) {
 		}
 	}
 
-	translatedArgs := t.Translate(args, mode)
+	translated, ok := translateFor(t, args, mode, pipe, tty)
+	if !ok {
+		printPassthrough(t.SourceTool(), args, format)
+		return
+	}
+	translatedArgs := validateArgs(t.SourceTool(), t.TargetTool(), translated, policy)
+
+	// JSON output is an argv array for shells without a usable eval
+	if format == "json" {
+		out, _ := json.Marshal(append([]string{t.TargetTool()}, translatedArgs...))
+		fmt.Println(string(out))
+		return
+	}
 
 	// Build and print the command
 	parts := make([]string, len(translatedArgs)+1)
@@ -199,56 +153,163 @@ func runTranslator(t translator.Translator, args []string, mode string) {
 
 func main() {
 	args := os.Args[1:]
+	translator.FlavorDetector = flavor.Detect
 
-	// Handle reflag's own flags
-	if len(args) == 0 {
-		printUsage()
-		return
+	// The shell plumbing keeps its own option parsing
+	if len(args) > 0 {
+		switch args[0] {
+		case "--license":
+			printLicense()
+			return
+		case "--rewrite":
+			runRewrite(args[1:])
+			return
+		case "--wrap":
+			runWrap(args[1:])
+			return
+		}
 	}
+	runCommandLine(args)
+}
 
-	switch args[0] {
-	case "--version", "-V":
-		printVersion("reflag")
-		return
-	case "--license":
-		printLicense()
-		return
-	case "--list", "-l":
-		translator.PrintTable(os.Stdout)
-		return
-	case "--help", "-h":
-		printUsage()
-		return
-	case "--init":
-		shell, add, remove := parseInitArgs(args[1:])
-		printInit(shell, add, remove)
+// runInit runs init or deinit, which share their arguments
+func runInit(args []string, print func(io.Writer, string, []string, initOptions) error) {
+	opts, rest, err := parseInitOptions(args)
+	if err == nil {
+		shell, selection := parseInitArgs(rest)
+		err = print(os.Stdout, shell, selection, opts)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// runList implements list [selection...]
+func runList(g globalOptions, args []string) {
+	// Filter with the init selection grammar, starting from every translator
+	names, err := translator.Select(translator.List(), args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if g.format == "json" {
+		out, _ := json.MarshalIndent(describeTranslators(names), "", "  ")
+		fmt.Println(string(out))
 		return
 	}
+	translator.PrintSelected(os.Stdout, names)
+}
 
-	// Parse --mode flag if present
-	mode := ""
-	if after, ok := strings.CutPrefix(args[0], "--mode="); ok {
-		mode = after
+// translatorInfo is a translator as reflag list --format=json shows it
+type translatorInfo struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Default bool   `json:"default"`
+	translator.Metadata
+}
+
+// describeTranslators returns the named translators' metadata, sorted by name
+func describeTranslators(names []string) []translatorInfo {
+	names = slices.Sorted(slices.Values(names))
+	infos := make([]translatorInfo, 0, len(names))
+	for _, name := range names {
+		t := translator.GetByName(name)
+		infos = append(infos, translatorInfo{name, t.SourceTool(), t.TargetTool(), t.IncludeInInit(), translator.Describe(t)})
+	}
+	return infos
+}
+
+// runTranslate implements translate and explain:
+// [--validate=POLICY] [--min-fidelity=LEVEL] [--pipe=POLICY] [--explain] <source> <target> [flags...]
+func runTranslate(g globalOptions, args []string, explain bool) {
+	policy := ""
+	minimum := ""
+	pipe := ""
+	for len(args) > 0 {
+		if after, ok := strings.CutPrefix(args[0], "--validate="); ok {
+			policy = after
+		} else if after, ok := strings.CutPrefix(args[0], "--min-fidelity="); ok {
+			minimum = after
+		} else if after, ok := strings.CutPrefix(args[0], "--pipe="); ok {
+			pipe = after
+		} else if args[0] == "--explain" {
+			explain = true
+		} else if args[0] == "--" {
+			args = args[1:]
+			break
+		} else {
+			break
+		}
 		args = args[1:]
-	} else if args[0] == "--mode" && len(args) > 1 {
-		mode = args[1]
-		args = args[2:]
 	}
 
-	// Explicit mode: reflag [--mode=MODE] <source> <target> [flags...]
+	if policy == "" {
+		policy = validatePolicy()
+	} else if err := checkPolicy(policy); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+
+	minFid := translator.Lossy
+	if minimum == "" {
+		minFid = minFidelity()
+	} else if f, err := translator.ParseFidelity(minimum); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	} else {
+		minFid = f
+	}
+
+	if pipe != "" {
+		if err := checkPipePolicy(pipe); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(exitError)
+		}
+	}
+
 	if len(args) < 2 {
 		fmt.Fprintln(os.Stderr, "error: expected <source> <target> arguments")
-		fmt.Fprintln(os.Stderr, "usage: reflag [--mode=MODE] <source> <target> [flags...]")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "usage: reflag [options] <source> <target> [flags...] (see reflag translate --help)")
+		os.Exit(exitError)
 	}
 
 	source, target := args[0], args[1]
 	t := translator.Get(source, target)
 	if t == nil {
 		fmt.Fprintf(os.Stderr, "error: no translator registered for %s to %s\n", source, target)
-		fmt.Fprintln(os.Stderr, "use 'reflag --list' to see available translators")
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "use 'reflag list' to see available translators")
+		os.Exit(exitRefused)
+	}
+
+	if pipe == "" {
+		pipe = pipePolicy(t.Name())
+	}
+	ctx := currentContext()
+	if explain {
+		printExplain(os.Stdout, userConfig(), t, args[2:], g.mode, minFid, pipe, ctx)
+		return
+	}
+
+	// Routing rules are checked before anything is translated
+	reason := ""
+	if translatorDisabled(t.Name()) {
+		reason = "switched off"
+	} else if r := userConfig().route(source, args[2:], ctx); r != nil {
+		reason = fmt.Sprintf("routed by rule %s (%s)", r.text, r.where)
+	} else if belowMinimum(t, args[2:], g.mode, minFid) {
+		reason = "graded below " + minFid.String()
+	}
+	if g.verbose {
+		fmt.Fprintf(os.Stderr, "reflag: %s, mode %s, config %s\n", t.Name(), describeMode(t, g.mode), configPath())
+	}
+	if reason != "" {
+		if g.verbose {
+			fmt.Fprintf(os.Stderr, "reflag: running %s untranslated: %s\n", source, reason)
+		}
+		printPassthrough(source, args[2:], g.format)
+		return
 	}
 
-	runTranslator(t, args[2:], mode)
+	runTranslator(t, args[2:], g.mode, g.format, policy, pipe, ctx.tty)
 }
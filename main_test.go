@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"github.com/kluzzebass/reflag/translator"
-	_ "github.com/kluzzebass/reflag/translator/ls2eza"
+	_ "github.com/kluzzebass/reflag/translator/all"
 )
 
 func TestShellQuote(t *testing.T) {
@@ -23,6 +23,13 @@ func TestShellQuote(t *testing.T) {
 		{"with`backtick", "'with`backtick'"},
 		{"with\\backslash", "'with\\backslash'"},
 		{"with!exclaim", "'with!exclaim'"},
+		{"", "''"},
+		{"*.go", "'*.go'"},
+		{"~", "'~'"},
+		{"a;b", "'a;b'"},
+		{"=ls", "'=ls'"},
+		{"--sort=modified", "--sort=modified"},
+		{"/tmp/file-1.txt", "/tmp/file-1.txt"},
 	}
 
 	for _, tt := range tests {
@@ -37,88 +44,81 @@ func TestShellQuote(t *testing.T) {
 
 func TestParseInitArgs(t *testing.T) {
 	tests := []struct {
-		name           string
-		args           []string
-		expectedShell  string
-		expectedAdd    []string
-		expectedRemove []string
+		name              string
+		args              []string
+		expectedShell     string
+		expectedSelection []string
 	}{
 		{
-			name:           "no args defaults to bash",
-			args:           []string{},
-			expectedShell:  "bash",
-			expectedAdd:    nil,
-			expectedRemove: nil,
+			name:              "no args defaults to bash",
+			args:              []string{},
+			expectedShell:     "bash",
+			expectedSelection: nil,
 		},
 		{
-			name:           "shell only",
-			args:           []string{"fish"},
-			expectedShell:  "fish",
-			expectedAdd:    nil,
-			expectedRemove: nil,
+			name:              "shell only",
+			args:              []string{"fish"},
+			expectedShell:     "fish",
+			expectedSelection: nil,
 		},
 		{
-			name:           "add translator",
-			args:           []string{"bash", "+dig2doggo"},
-			expectedShell:  "bash",
-			expectedAdd:    []string{"dig2doggo"},
-			expectedRemove: nil,
+			name:              "add translator",
+			args:              []string{"bash", "+dig2doggo"},
+			expectedShell:     "bash",
+			expectedSelection: []string{"+dig2doggo"},
 		},
 		{
-			name:           "remove translator",
-			args:           []string{"zsh", "-ls2eza"},
-			expectedShell:  "zsh",
-			expectedAdd:    nil,
-			expectedRemove: []string{"ls2eza"},
+			name:              "remove translator",
+			args:              []string{"zsh", "-ls2eza"},
+			expectedShell:     "zsh",
+			expectedSelection: []string{"-ls2eza"},
 		},
 		{
-			name:           "add and remove",
-			args:           []string{"fish", "+dig2doggo", "-ls2eza"},
-			expectedShell:  "fish",
-			expectedAdd:    []string{"dig2doggo"},
-			expectedRemove: []string{"ls2eza"},
+			name:              "add and remove",
+			args:              []string{"fish", "+dig2doggo", "-ls2eza"},
+			expectedShell:     "fish",
+			expectedSelection: []string{"+dig2doggo", "-ls2eza"},
 		},
 		{
-			name:           "multiple adds",
-			args:           []string{"+dig2doggo", "+more2moor"},
-			expectedShell:  "bash",
-			expectedAdd:    []string{"dig2doggo", "more2moor"},
-			expectedRemove: nil,
+			name:              "multiple adds",
+			args:              []string{"+dig2doggo", "+more2moor"},
+			expectedShell:     "bash",
+			expectedSelection: []string{"+dig2doggo", "+more2moor"},
 		},
 		{
-			name:           "multiple removes",
-			args:           []string{"zsh", "-ls2eza", "-grep2rg"},
-			expectedShell:  "zsh",
-			expectedAdd:    nil,
-			expectedRemove: []string{"ls2eza", "grep2rg"},
+			name:              "multiple removes",
+			args:              []string{"zsh", "-ls2eza", "-grep2rg"},
+			expectedShell:     "zsh",
+			expectedSelection: []string{"-ls2eza", "-grep2rg"},
 		},
 		{
-			name:           "shell at end",
-			args:           []string{"+dig2doggo", "-ls2eza", "fish"},
-			expectedShell:  "fish",
-			expectedAdd:    []string{"dig2doggo"},
-			expectedRemove: []string{"ls2eza"},
+			name:              "shell at end",
+			args:              []string{"+dig2doggo", "-ls2eza", "fish"},
+			expectedShell:     "fish",
+			expectedSelection: []string{"+dig2doggo", "-ls2eza"},
 		},
 		{
-			name:           "multiple shells takes last",
-			args:           []string{"bash", "fish", "zsh"},
-			expectedShell:  "zsh",
-			expectedAdd:    nil,
-			expectedRemove: nil,
+			name:              "bare names",
+			args:              []string{"bash", "ls2eza", "grep2rg"},
+			expectedShell:     "bash",
+			expectedSelection: []string{"ls2eza", "grep2rg"},
+		},
+		{
+			name:              "multiple shells takes last",
+			args:              []string{"bash", "fish", "zsh"},
+			expectedShell:     "zsh",
+			expectedSelection: nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			shell, add, remove := parseInitArgs(tt.args)
+			shell, selection := parseInitArgs(tt.args)
 			if shell != tt.expectedShell {
 				t.Errorf("parseInitArgs(%v) shell = %q, want %q", tt.args, shell, tt.expectedShell)
 			}
-			if !slices.Equal(add, tt.expectedAdd) {
-				t.Errorf("parseInitArgs(%v) add = %v, want %v", tt.args, add, tt.expectedAdd)
-			}
-			if !slices.Equal(remove, tt.expectedRemove) {
-				t.Errorf("parseInitArgs(%v) remove = %v, want %v", tt.args, remove, tt.expectedRemove)
+			if !slices.Equal(selection, tt.expectedSelection) {
+				t.Errorf("parseInitArgs(%v) selection = %v, want %v", tt.args, selection, tt.expectedSelection)
 			}
 		})
 	}
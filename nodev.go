@@ -0,0 +1,6 @@
+//go:build !dev
+
+package main
+
+// devCommands is empty without -tags dev; see dev.go
+var devCommands []*command
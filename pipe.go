@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// Pipe policies for commands whose output doesn't go to a terminal
+const (
+	pipeAlways     = "always"            // translate as usual (the default)
+	pipeTTYOnly    = "tty-only"          // run the source tool
+	pipeCompatible = "tty-or-compatible" // ask the target for parseable output, else run the source tool
+)
+
+// pipeEnv overrides the config file's pipe settings
+const pipeEnv = "REFLAG_PIPE"
+
+// checkPipePolicy returns an error for an unknown pipe policy
+func checkPipePolicy(policy string) error {
+	switch policy {
+	case pipeAlways, pipeTTYOnly, pipeCompatible:
+		return nil
+	}
+	return fmt.Errorf("unknown pipe policy %q (expected always, tty-only or tty-or-compatible)", policy)
+}
+
+// pipePolicy returns the pipe policy for translator name from $REFLAG_PIPE,
+// or the config file's pipe.NAME or pipe setting
+func pipePolicy(name string) string {
+	policy := os.Getenv(pipeEnv)
+	if policy == "" {
+		policy = userConfig().pipeFor[name]
+	}
+	if policy == "" {
+		policy = userConfig().pipe
+	}
+	if policy == "" {
+		return pipeAlways
+	}
+	if err := checkPipePolicy(policy); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v\n", pipeEnv, err)
+		return pipeAlways
+	}
+	return policy
+}
+
+// translateFor translates args with t for output that goes to a terminal or,
+// when tty is false, is read by another program under policy. It returns
+// false when the source tool should run instead.
+func translateFor(t translator.Translator, args []string, mode, policy string, tty bool) ([]string, bool) {
+	if tty {
+		return t.Translate(args, mode), true
+	}
+	switch policy {
+	case pipeTTYOnly:
+		return nil, false
+	case pipeCompatible:
+		return translator.TranslatePiped(t, args, mode)
+	}
+	return t.Translate(args, mode), true
+}
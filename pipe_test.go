@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+func TestTranslateFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		translator string
+		args       []string
+		policy     string
+		tty        bool
+		want       []string // nil when the source tool should run
+	}{
+		{"terminal", "ls2eza", []string{"-a"}, pipeTTYOnly, true, []string{"-a"}},
+		{"always", "ls2eza", []string{"-a"}, pipeAlways, false, []string{"-a"}},
+		{"tty-only", "ls2eza", []string{"-a"}, pipeTTYOnly, false, nil},
+		{"compatible names", "ls2eza", []string{"-a"}, pipeCompatible, false, []string{"-1", "-a"}},
+		{"compatible long listing", "ls2eza", []string{"-la"}, pipeCompatible, false, nil},
+		{"compatible json", "df2duf", []string{"-h"}, pipeCompatible, false, []string{"-json"}},
+		{"no compatible form", "ps2procs", []string{"aux"}, pipeCompatible, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := translator.GetByName(tt.translator)
+			got, ok := translateFor(tr, tt.args, "gnu", tt.policy, tt.tty)
+			if ok != (tt.want != nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("translateFor(%q, %s, tty=%v) = %q, %v; want %q", tt.args, tt.policy, tt.tty, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestShInitPipePolicy(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{pipeTTYOnly, []string{"-at", "dir"}},
+		{pipeCompatible, []string{"-1", "-a", "--sort=modified", "--reverse", "dir"}},
+	}
+
+	for _, sh := range posixShells {
+		for _, tt := range tests {
+			t.Run(sh.name+"/"+tt.policy, func(t *testing.T) {
+				bin := fakeBin(t, "reflag", "ls", "eza")
+				// The fake tools' output is read by the test, so it is not a terminal
+				script := `REFLAG_PIPE=` + tt.policy + `; export REFLAG_PIPE; ls "$@"`
+				got, _ := runShInit(t, sh.cmd, sh.init, bin, script, []string{"-at", "dir"})
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("argv = %q, want %q", got, tt.want)
+				}
+			})
+		}
+	}
+}
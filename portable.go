@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// portableRunner holds the helpers every portable wrapper calls.
+// __reflag_off is shRunner's. __reflag_add appends target arguments to
+// __reflag_flags, skipping any already there. __reflag_exec runs the
+// target with __reflag_flags and the operands in "$@", the arguments before
+// -- that aren't flags; the flags are plain words, so eval can split them
+// in every shell, zsh included.
+const portableRunner = `__reflag_off() {
+    case $1 in
+        ''|0) return 1 ;;
+        until:*) [ "$(date +%s)" -lt "${1#until:}" ] ;;
+        *) return 0 ;;
+    esac
+}
+
+__reflag_add() {
+    for __reflag_w do
+        case " $__reflag_flags " in
+            *" $__reflag_w "*) ;;
+            *) __reflag_flags="$__reflag_flags $__reflag_w" ;;
+        esac
+    done
+}
+
+__reflag_exec() {
+    __reflag_tgt=$1
+    shift
+    __reflag_n=$#
+    __reflag_opts=1
+    while [ "$__reflag_n" -gt 0 ]; do
+        case $__reflag_opts$1 in
+            1--) __reflag_opts=0; set -- "$@" "$1" ;;
+            1-?*) ;;
+            *) set -- "$@" "$1" ;;
+        esac
+        shift
+        __reflag_n=$((__reflag_n - 1))
+    done
+    eval "command $__reflag_tgt$__reflag_flags"' "$@"'
+}
+`
+
+// portableWord matches target arguments that are safe to eval unquoted
+var portableWord = regexp.MustCompile(`^[A-Za-z0-9_.,:=+/%@-]+$`)
+
+// portableArgs returns the __reflag_add call emitting args, "" for none,
+// and false when an argument isn't safe to emit
+func portableArgs(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", true
+	}
+	for _, arg := range args {
+		if !portableWord.MatchString(arg) {
+			return "", false
+		}
+	}
+	return "__reflag_add " + strings.Join(args, " "), true
+}
+
+// checkPortable rejects --portable for shells other than the sh family and
+// with options that need reflag at run time
+func checkPortable(shell string, opts initOptions) error {
+	if !opts.portable {
+		return nil
+	}
+	switch shell {
+	case "fish", "nu", "elvish", "xonsh":
+		return fmt.Errorf("--portable is only available for bash, zsh, sh and ksh")
+	}
+	if opts.widget != "" || len(opts.wrap) > 0 {
+		return fmt.Errorf("--portable can't be combined with --widget or --wrap")
+	}
+	return nil
+}
+
+// portableTranslators splits names into translators with flag tables and
+// the rest
+func portableTranslators(names []string) (tabled, skipped []string) {
+	for _, name := range names {
+		if _, ok := translator.GetByName(name).(translator.Tabled); ok {
+			tabled = append(tabled, name)
+		} else {
+			skipped = append(skipped, name)
+		}
+	}
+	return tabled, skipped
+}
+
+// writePortableInit emits wrappers that translate with the translators'
+// flag tables (see translator.Tabled) in plain POSIX sh, for hosts without
+// reflag. A command using anything beyond the tables, or run where the
+// target tool is missing, runs the source tool untranslated.
+func writePortableInit(w io.Writer, names []string) {
+	names, skipped := portableTranslators(names)
+	fmt.Fprintln(w, "# reflag portable shell init - paste into ~/.bashrc (or ~/.zshrc, ~/.kshrc) on any host;")
+	fmt.Fprintln(w, "# reflag itself need not be installed there")
+	fmt.Fprintln(w)
+	if len(skipped) > 0 {
+		fmt.Fprintf(w, "# Skipped because they have no flag tables: %s\n\n", strings.Join(skipped, ", "))
+	}
+	io.WriteString(w, portableRunner)
+	fmt.Fprintln(w)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		src, tgt := t.SourceTool(), t.TargetTool()
+		fmt.Fprintf(w, "__reflag_a=$(alias %s 2>/dev/null) && %s=$__reflag_a\n", src, savedAliasVar(src))
+		fmt.Fprintf(w, "unalias %s 2>/dev/null || :\n", src)
+		fmt.Fprintf(w, "%s() {\n", src)
+		fmt.Fprintf(w, "    if __reflag_off \"${REFLAG_DISABLE-}\" || __reflag_off \"${%s-}\" ||\n", disableEnvFor(name))
+		fmt.Fprintf(w, "        ! command -v %s >/dev/null 2>&1; then\n", tgt)
+		fmt.Fprintf(w, "        command %s \"$@\"\n", src)
+		fmt.Fprintln(w, "        return")
+		fmt.Fprintln(w, "    fi")
+		writePortableBody(w, t.(translator.Tabled).Table(), src, tgt)
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+// writePortableBody emits a wrapper's translation: a loop over the
+// arguments up to -- that collects the target flags, falling back to the
+// source tool at the first flag outside table
+func writePortableBody(w io.Writer, table translator.Table, src, tgt string) {
+	fallback := fmt.Sprintf("command %s \"$@\"; return", src)
+	rule := table.Reverse != "" && portableWord.MatchString(table.Reverse)
+
+	// actions returns the commands translating flag, or false when it is
+	// beyond the table
+	actions := func(flag string, args []string, ok bool) (string, bool) {
+		var cmds []string
+		if rule && slices.Contains(table.ReverseFlags, flag) {
+			return "__reflag_rev=1", true
+		}
+		if !ok {
+			return "", false
+		}
+		if rule && slices.Contains(table.SortFlags, flag) {
+			cmds = append(cmds, "__reflag_sort=1")
+		}
+		add, safe := portableArgs(args)
+		if !safe {
+			return "", false
+		}
+		if add != "" {
+			cmds = append(cmds, add)
+		}
+		if len(cmds) == 0 {
+			return ":", true
+		}
+		return strings.Join(cmds, "; "), true
+	}
+
+	fmt.Fprintln(w, "    __reflag_flags= __reflag_sort=0 __reflag_rev=0")
+	fmt.Fprintln(w, "    for __reflag_arg do")
+	fmt.Fprintln(w, "        case $__reflag_arg in")
+	fmt.Fprintln(w, "            --) break ;;")
+	longs := slices.Collect(maps.Keys(table.Long))
+	for _, flag := range table.ReverseFlags {
+		if strings.HasPrefix(flag, "--") && !slices.Contains(longs, flag) {
+			longs = append(longs, flag)
+		}
+	}
+	slices.Sort(longs)
+	for _, flag := range longs {
+		args, ok := table.Long[flag]
+		if cmd, ok := actions(flag, args, ok); ok {
+			fmt.Fprintf(w, "            %s) %s ;;\n", shellQuote(flag), cmd)
+		}
+	}
+	fmt.Fprintf(w, "            --*) %s ;;\n", fallback)
+	fmt.Fprintln(w, "            -?*)")
+	fmt.Fprintln(w, "                __reflag_f=${__reflag_arg#-}")
+	fmt.Fprintln(w, "                while [ -n \"$__reflag_f\" ]; do")
+	fmt.Fprintln(w, "                    __reflag_c=${__reflag_f%\"${__reflag_f#?}\"}")
+	fmt.Fprintln(w, "                    __reflag_f=${__reflag_f#?}")
+	fmt.Fprintln(w, "                    case $__reflag_c in")
+	letters := slices.Collect(maps.Keys(table.Short))
+	for _, flag := range table.ReverseFlags {
+		if len(flag) == 2 && flag[0] == '-' && !slices.Contains(letters, rune(flag[1])) {
+			letters = append(letters, rune(flag[1]))
+		}
+	}
+	slices.Sort(letters)
+	for _, c := range letters {
+		args, ok := table.Short[c]
+		if cmd, ok := actions("-"+string(c), args, ok); ok {
+			fmt.Fprintf(w, "                        %s) %s ;;\n", shellQuote(string(c)), cmd)
+		}
+	}
+	fmt.Fprintf(w, "                        *) %s ;;\n", fallback)
+	fmt.Fprintln(w, "                    esac")
+	fmt.Fprintln(w, "                done")
+	fmt.Fprintln(w, "                ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "    done")
+	if rule {
+		fmt.Fprintf(w, "    [ \"$__reflag_sort\" = \"$__reflag_rev\" ] || __reflag_add %s\n", table.Reverse)
+	}
+	fmt.Fprintf(w, "    __reflag_exec %s \"$@\"\n", tgt)
+}
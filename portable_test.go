@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPortableInit(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		argv   []string
+		want   []string
+	}{
+		{"ls flags", `ls "$@"`, []string{"-la", "--group-directories-first", "x"}, []string{"-l", "-a", "--group-directories-first", "x"}},
+		{"ls sort", `ls "$@"`, []string{"-lt", "dir"}, []string{"-l", "--sort=modified", "--reverse", "dir"}},
+		{"ls sort reversed", `ls "$@"`, []string{"-ltr", "dir"}, []string{"-l", "--sort=modified", "dir"}},
+		{"ls reverse only", `ls "$@"`, []string{"-r"}, []string{"--reverse"}},
+		{"ls operands", `ls "$@"`, []string{"-1", "with space", "--", "-dash", "$HOME"}, []string{"-1", "with space", "--", "-dash", "$HOME"}},
+		{"grep ignored", `grep "$@"`, []string{"-rni", "foo", "."}, []string{"-n", "-i", "foo", "."}},
+		{"grep duplicate", `grep "$@"`, []string{"-Z", "-l", "--null", "pat"}, []string{"-0", "-l", "pat"}},
+
+		// Beyond the tables: the source tool runs untranslated
+		{"ls value", `ls "$@"`, []string{"-l", "-w", "80"}, []string{"-l", "-w", "80"}},
+		{"ls long value", `ls "$@"`, []string{"--color=auto", "x"}, []string{"--color=auto", "x"}},
+		{"grep context", `grep "$@"`, []string{"-A", "2", "pat"}, []string{"-A", "2", "pat"}},
+		{"grep ignored value", `grep "$@"`, []string{"-d", "skip", "pat", "."}, []string{"-d", "skip", "pat", "."}},
+		{"grep ignored bundled value", `grep "$@"`, []string{"-iD", "read", "pat"}, []string{"-iD", "read", "pat"}},
+		{"disabled", `REFLAG_DISABLE=1; ls "$@"`, []string{"-lt"}, []string{"-lt"}},
+	}
+
+	// No reflag: the output must stand on its own
+	bin := fakeBin(t, "ls", "eza", "grep", "rg")
+	for _, sh := range posixShells {
+		for _, tt := range tests {
+			t.Run(sh.name+"/"+tt.name, func(t *testing.T) {
+				got, _ := runShInitOpts(t, sh.cmd, sh.init, initOptions{portable: true}, bin, tt.script, tt.argv)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("argv = %q, want %q", got, tt.want)
+				}
+			})
+		}
+
+		t.Run(sh.name+"/missing target", func(t *testing.T) {
+			argv := []string{"-lt", "with space"}
+			got, _ := runShInitOpts(t, sh.cmd, sh.init, initOptions{portable: true}, fakeBin(t, "ls"), `ls "$@"`, argv)
+			if !slices.Equal(got, argv) {
+				t.Errorf("argv = %q, want %q", got, argv)
+			}
+		})
+	}
+}
+
+func TestPrintInitPortable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printInit(&buf, "bash", []string{"ls2eza", "find2fd"}, initOptions{portable: true}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"ls() {", "# Skipped because they have no flag tables: find2fd"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("portable init missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "command reflag") {
+		t.Errorf("portable init should not call reflag:\n%s", out)
+	}
+
+	for _, tt := range []struct {
+		shell string
+		opts  initOptions
+	}{
+		{"fish", initOptions{portable: true}},
+		{"bash", initOptions{portable: true, widget: "key"}},
+		{"bash", initOptions{portable: true, wrap: []string{"sudo"}}},
+	} {
+		if err := printInit(&bytes.Buffer{}, tt.shell, nil, tt.opts); err == nil {
+			t.Errorf("printInit(%s, %+v) should fail", tt.shell, tt.opts)
+		}
+	}
+}
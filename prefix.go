@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// prefixCommand describes the options of a command that runs another command
+// (sudo ls -l), so the wrapped command can be found in its arguments
+type prefixCommand struct {
+	argShort    string   // short options taking an argument (-u user, -ubob)
+	optShort    string   // short options taking an optional attached argument (-I{}, -i)
+	argLong     []string // long options taking an argument (--user bob, --user=bob)
+	noCommand   string   // short options meaning no command is run (sudo -l)
+	noCmdLong   []string // long options meaning no command is run
+	assignments bool     // NAME=VALUE words may come before the command
+}
+
+// prefixCommands lists the prefix commands reflag can wrap. GNU and BSD
+// options are merged; where they clash the one taking an argument wins, as
+// misreading an argument as the command is the worse mistake.
+var prefixCommands = map[string]*prefixCommand{
+	"sudo": {
+		argShort:    "CDgprtTuU",
+		argLong:     []string{"chdir", "close-from", "command-timeout", "group", "host", "other-user", "prompt", "role", "type", "user"},
+		noCommand:   "ehKlvV",
+		noCmdLong:   []string{"edit", "help", "list", "remove-timestamp", "validate", "version"},
+		assignments: true,
+	},
+	"watch": {
+		argShort:  "nq",
+		argLong:   []string{"interval", "equexit"},
+		noCommand: "hv",
+		noCmdLong: []string{"help", "version"},
+	},
+	"xargs": {
+		argShort:  "adEILnPsJRS",
+		optShort:  "eil",
+		argLong:   []string{"arg-file", "delimiter", "max-args", "max-procs", "max-chars", "process-slot-var"},
+		noCmdLong: []string{"help", "version", "show-limits"},
+	},
+	"env": {
+		argShort:    "uC",
+		argLong:     []string{"unset", "chdir"},
+		noCommand:   "S", // -S splits a string into the command; leave it alone
+		noCmdLong:   []string{"split-string", "help", "version"},
+		assignments: true,
+	},
+	"time": {
+		argShort: "fo",
+		argLong:  []string{"format", "output"},
+	},
+	"nice": {
+		argShort:  "n",
+		argLong:   []string{"adjustment"},
+		noCmdLong: []string{"help", "version"},
+	},
+}
+
+// prefixNames returns the sorted names of the prefix commands
+func prefixNames() []string {
+	names := make([]string, 0, len(prefixCommands))
+	for name := range prefixCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitPrefix returns the index of the wrapped command in args, or -1 if
+// there is none (options only, or an option that means nothing is run)
+func (p *prefixCommand) splitPrefix(args []string) int {
+	i := 0
+	for i < len(args) {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			i++
+			return p.skipAssignments(args, i)
+		case arg == "-":
+			i++ // env - is the old spelling of env -i
+		case !strings.HasPrefix(arg, "-"):
+			return p.skipAssignments(args, i)
+		case strings.HasPrefix(arg, "--"):
+			name, _, hasValue := strings.Cut(arg[2:], "=")
+			if slices.Contains(p.noCmdLong, name) {
+				return -1
+			}
+			if !hasValue && slices.Contains(p.argLong, name) {
+				i++
+			}
+			i++
+		default:
+			for j := 1; j < len(arg); j++ {
+				c := arg[j]
+				if strings.IndexByte(p.noCommand, c) >= 0 {
+					return -1
+				}
+				if strings.IndexByte(p.argShort, c) >= 0 {
+					// The argument is the rest of this word or the next one
+					if j == len(arg)-1 {
+						i++
+					}
+					break
+				}
+				if strings.IndexByte(p.optShort, c) >= 0 {
+					break
+				}
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+// skipAssignments skips NAME=VALUE words at i for commands that take them
+func (p *prefixCommand) skipAssignments(args []string, i int) int {
+	if p.assignments {
+		for i < len(args) && assignmentRe.MatchString(args[i]) {
+			i++
+		}
+	}
+	if i >= len(args) {
+		return -1
+	}
+	return i
+}
+
+// translatePrefixed returns the argv for running prefix with args, with the
+// wrapped command translated by the first of names whose source tool it is.
+// Nested prefixes (sudo nice ls) are followed. Commands given by path
+// (sudo /bin/ls) and translators switched off are left alone.
+func translatePrefixed(prefix string, args []string, names []string, mode string) []string {
+	out := append([]string{prefix}, args...)
+	p := prefixCommands[prefix]
+	if p == nil {
+		return out
+	}
+	i := p.splitPrefix(args)
+	if i < 0 {
+		return out
+	}
+
+	cmd, rest := args[i], args[i+1:]
+	if _, ok := prefixCommands[cmd]; ok {
+		return append(out[:i+1], translatePrefixed(cmd, rest, names, mode)...)
+	}
+	for _, name := range names {
+		t := translator.GetByName(name)
+		if t == nil || t.SourceTool() != cmd || translatorDisabled(name) {
+			continue
+		}
+		ctx := currentContext()
+		if userConfig().route(cmd, rest, ctx) != nil || belowMinimum(t, rest, mode, minFidelity()) {
+			continue
+		}
+		translated, ok := translateFor(t, rest, mode, pipePolicy(name), ctx.tty)
+		if !ok {
+			continue
+		}
+		out = append(out[:i+1], t.TargetTool())
+		return append(out, validateArgs(t.SourceTool(), t.TargetTool(), translated, validatePolicy())...)
+	}
+	return out
+}
+
+// runWrap implements --wrap [--mode=MODE] [--format=FORMAT] [--translators=a,b] PREFIX [args...]
+// and prints the prefix command with the command it runs translated. The
+// shell form starts with command so a wrapper function can eval it without
+// calling itself; the JSON form resolves the prefix to a path for the same reason.
+func runWrap(args []string) {
+	mode := ""
+	format := "shell"
+	var names []string
+	explicit := false
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		if after, ok := strings.CutPrefix(arg, "--mode="); ok {
+			mode = after
+		} else if after, ok := strings.CutPrefix(arg, "--format="); ok && (after == "shell" || after == "json") {
+			format = after
+		} else if after, ok := strings.CutPrefix(arg, "--translators="); ok {
+			explicit = true
+			if after != "" {
+				names = selectTranslators(splitList(after))
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "error: unknown --wrap option %q\n", arg)
+			os.Exit(exitError)
+		}
+	}
+	if !explicit {
+		names = selectTranslators(nil)
+	}
+
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: reflag --wrap [--mode=MODE] [--format=FORMAT] [--translators=NAME,...] PREFIX [args...]")
+		os.Exit(exitError)
+	}
+	if prefixCommands[args[0]] == nil {
+		fmt.Fprintf(os.Stderr, "error: %q is not a supported prefix command (supported: %s)\n", args[0], strings.Join(prefixNames(), ", "))
+		os.Exit(exitRefused)
+	}
+
+	argv := translatePrefixed(args[0], args[1:], names, mode)
+	if format == "json" {
+		if path, err := exec.LookPath(argv[0]); err == nil {
+			argv[0] = path
+		}
+		out, _ := json.Marshal(argv)
+		fmt.Println(string(out))
+		return
+	}
+	parts := []string{"command"}
+	for _, arg := range argv {
+		parts = append(parts, shellQuote(arg))
+	}
+	fmt.Println(strings.Join(parts, " "))
+}
+
+// checkWrap rejects --wrap where there are no wrapper functions to add to
+func checkWrap(shell string, opts initOptions) error {
+	if len(opts.wrap) == 0 {
+		return nil
+	}
+	if opts.widget != "" {
+		return fmt.Errorf("--wrap can't be combined with --widget")
+	}
+	switch shell {
+	case "nu", "elvish", "xonsh":
+		return fmt.Errorf("--wrap is only available for bash, zsh, sh, ksh and fish")
+	}
+	return nil
+}
+
+// shWrapRunner is the __reflag_run counterpart for prefix commands. It takes
+// the prefix command, the value of its REFLAG_DISABLE_<NAME> variable and the
+// comma-separated translators to apply to the wrapped command, then the
+// arguments.
+const shWrapRunner = `__reflag_wrap() {
+    __reflag_src=$1
+    __reflag_names=$3
+    if __reflag_off "${REFLAG_DISABLE-}" || __reflag_off "$2"; then
+        shift 3
+        command "$__reflag_src" "$@"
+        return
+    fi
+    shift 3
+    [ -t 1 ] && __reflag_tty=1 || __reflag_tty=0
+    __reflag_out=$(REFLAG_TTY=$__reflag_tty command reflag --wrap --translators="$__reflag_names" "$__reflag_src" "$@")
+    __reflag_status=$?
+    if [ "$__reflag_status" -ne 0 ] || [ -z "$__reflag_out" ]; then
+        if [ "$__reflag_status" -ne 3 ]; then
+            echo "reflag: translation failed (exit $__reflag_status), running $__reflag_src directly" >&2
+        fi
+        command "$__reflag_src" "$@"
+        return
+    fi
+    eval "$__reflag_out"
+}
+`
+
+// fishWrapRunner is the fish counterpart of shWrapRunner
+const fishWrapRunner = `function __reflag_wrap
+    set -l src $argv[1]
+    if __reflag_off "$REFLAG_DISABLE"; or __reflag_off "$argv[2]"
+        command $src $argv[4..-1]
+        return
+    end
+    set -lx REFLAG_TTY 0
+    isatty stdout; and set REFLAG_TTY 1
+    set -l out (command reflag --wrap --translators=$argv[3] $src $argv[4..-1])
+    set -l st $status
+    if test $st -ne 0; or test -z "$out"
+        if test $st -ne 3
+            echo "reflag: translation failed (exit $st), running $src directly" >&2
+        end
+        command $src $argv[4..-1]
+        return
+    end
+    eval $out
+end
+`
+
+// writeShPrefixWrappers emits wrappers for the prefix commands in wrap that
+// translate the command they run with names. time is a reserved word in
+// bash, zsh and ksh, where it already times the wrapper functions, and
+// defining a function called time there is a syntax error, so its wrapper is
+// only defined (through eval) where time is an ordinary command.
+func writeShPrefixWrappers(w io.Writer, wrap []string, names []string) {
+	if len(wrap) == 0 {
+		return
+	}
+	io.WriteString(w, shWrapRunner)
+	fmt.Fprintln(w)
+	list := strings.Join(names, ",")
+	for _, prefix := range wrap {
+		call := fmt.Sprintf("__reflag_wrap %s \"${%s-}\" \"%s\" \"$@\"", prefix, disableEnvFor(prefix), list)
+		save := fmt.Sprintf("__reflag_a=$(alias %s 2>/dev/null) && %s=$__reflag_a", prefix, savedAliasVar(prefix))
+		if prefix == "time" {
+			fmt.Fprintln(w, "case $(command -V time 2>/dev/null) in")
+			fmt.Fprintln(w, "    *keyword*|*reserved*) ;;")
+			fmt.Fprintln(w, "    *)")
+			fmt.Fprintf(w, "        %s\n", save)
+			fmt.Fprintln(w, "        unalias time 2>/dev/null || :")
+			fmt.Fprintf(w, "        eval 'time() { %s; }'\n", call)
+			fmt.Fprintln(w, "        ;;")
+			fmt.Fprintln(w, "esac")
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintln(w, save)
+		fmt.Fprintf(w, "unalias %s 2>/dev/null || :\n", prefix)
+		fmt.Fprintf(w, "%s() {\n", prefix)
+		fmt.Fprintf(w, "    %s\n", call)
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+// writeFishPrefixWrappers is the fish counterpart of writeShPrefixWrappers.
+// time is a fish keyword that already times the wrappers, so it is skipped.
+func writeFishPrefixWrappers(w io.Writer, wrap []string, names []string) {
+	if len(wrap) == 0 {
+		return
+	}
+	io.WriteString(w, fishWrapRunner)
+	fmt.Fprintln(w)
+	list := strings.Join(names, ",")
+	for _, prefix := range wrap {
+		if prefix == "time" {
+			fmt.Fprintln(w, "# time is a fish keyword and already times the wrappers")
+			fmt.Fprintln(w)
+			continue
+		}
+		writeFishSaveFunction(w, prefix)
+		fmt.Fprintf(w, "functions -e %s 2>/dev/null\n", prefix)
+		fmt.Fprintf(w, "function %s\n", prefix)
+		fmt.Fprintf(w, "    __reflag_wrap %s \"$%s\" \"%s\" $argv\n", prefix, disableEnvFor(prefix), list)
+		fmt.Fprintln(w, "end")
+		fmt.Fprintln(w)
+	}
+}
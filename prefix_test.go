@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTranslatePrefixed(t *testing.T) {
+	names := []string{"du2dust", "grep2rg", "ls2eza", "ps2procs"}
+
+	tests := []struct {
+		name string
+		argv []string
+		want []string
+	}{
+		{"sudo user", []string{"sudo", "-u", "bob", "ls", "-l"}, []string{"sudo", "-u", "bob", "eza", "-l"}},
+		{"sudo attached user", []string{"sudo", "-Eubob", "ls", "-l"}, []string{"sudo", "-Eubob", "eza", "-l"}},
+		{"sudo long option", []string{"sudo", "--user", "bob", "--preserve-env", "ls", "-l"}, []string{"sudo", "--user", "bob", "--preserve-env", "eza", "-l"}},
+		{"sudo assignment", []string{"sudo", "LC_ALL=C", "ls", "-l"}, []string{"sudo", "LC_ALL=C", "eza", "-l"}},
+		{"sudo list runs nothing", []string{"sudo", "-l", "ls"}, []string{"sudo", "-l", "ls"}},
+		{"sudo edit runs nothing", []string{"sudo", "--edit", "ls"}, []string{"sudo", "--edit", "ls"}},
+		{"path is left alone", []string{"sudo", "/bin/ls", "-l"}, []string{"sudo", "/bin/ls", "-l"}},
+		{"unselected tool", []string{"sudo", "cat", "-n", "f"}, []string{"sudo", "cat", "-n", "f"}},
+		{"watch interval", []string{"watch", "-n", "2", "ps", "aux"}, []string{"watch", "-n", "2", "procs", "--pager", "disable"}},
+		{"watch attached interval", []string{"watch", "-n2", "-d", "ps", "aux"}, []string{"watch", "-n2", "-d", "procs", "--pager", "disable"}},
+		{"xargs", []string{"xargs", "-0", "-I{}", "grep", "-l", "foo", "{}"}, []string{"xargs", "-0", "-I{}", "rg", "-l", "foo", "{}"}},
+		{"xargs separate replace", []string{"xargs", "-I", "{}", "-P", "4", "grep", "-l", "foo"}, []string{"xargs", "-I", "{}", "-P", "4", "rg", "-l", "foo"}},
+		{"xargs optional eof", []string{"xargs", "-e", "grep", "x"}, []string{"xargs", "-e", "rg", "x"}},
+		{"xargs without command", []string{"xargs", "-0"}, []string{"xargs", "-0"}},
+		{"env", []string{"env", "-i", "-u", "HOME", "FOO=1", "ls", "-a"}, []string{"env", "-i", "-u", "HOME", "FOO=1", "eza", "-a"}},
+		{"env dash", []string{"env", "-", "PATH=/bin", "ls"}, []string{"env", "-", "PATH=/bin", "eza"}},
+		{"env split string", []string{"env", "-S", "ls -l"}, []string{"env", "-S", "ls -l"}},
+		{"time", []string{"time", "-p", "du", "-s"}, []string{"time", "-p", "dust", "-d", "0"}},
+		{"time output file", []string{"time", "-o", "log", "du", "-s"}, []string{"time", "-o", "log", "dust", "-d", "0"}},
+		{"nice", []string{"nice", "-n", "10", "du", "-s"}, []string{"nice", "-n", "10", "dust", "-d", "0"}},
+		{"nice legacy", []string{"nice", "-10", "du", "-s"}, []string{"nice", "-10", "dust", "-d", "0"}},
+		{"nested", []string{"sudo", "-u", "bob", "nice", "-n", "5", "ls", "-l"}, []string{"sudo", "-u", "bob", "nice", "-n", "5", "eza", "-l"}},
+		{"end of options", []string{"nice", "--", "ls"}, []string{"nice", "--", "eza"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translatePrefixed(tt.argv[0], tt.argv[1:], names, "")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("translatePrefixed(%q) = %q, want %q", tt.argv, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslatePrefixedDisabled(t *testing.T) {
+	t.Setenv("REFLAG_DISABLE_LS2EZA", "1")
+	got := translatePrefixed("sudo", []string{"ls", "-l"}, []string{"ls2eza"}, "")
+	if want := []string{"sudo", "ls", "-l"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTranslatePrefixedMinFidelity(t *testing.T) {
+	t.Setenv(minFidelityEnv, "exact")
+	names := []string{"grep2rg"}
+	got := translatePrefixed("sudo", []string{"grep", "-s", "foo"}, names, "")
+	if want := []string{"sudo", "grep", "-s", "foo"}; !slices.Equal(got, want) {
+		t.Errorf("lossy translation = %q, want %q", got, want)
+	}
+	got = translatePrefixed("sudo", []string{"grep", "-i", "foo"}, names, "")
+	if want := []string{"sudo", "rg", "-i", "foo"}; !slices.Equal(got, want) {
+		t.Errorf("exact translation = %q, want %q", got, want)
+	}
+}
+
+func TestPrintInitWrap(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printInit(&buf, "bash", []string{"ls2eza"}, initOptions{all: true, wrap: prefixNames()}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"__reflag_wrap() {\n",
+		"sudo() {\n    __reflag_wrap sudo \"${REFLAG_DISABLE_SUDO-}\" \"ls2eza\" \"$@\"\n}\n",
+		"    *keyword*|*reserved*) ;;\n",
+		"eval 'time() { __reflag_wrap time \"${REFLAG_DISABLE_TIME-}\" \"ls2eza\" \"$@\"; }'\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	if err := printInit(&buf, "fish", []string{"ls2eza"}, initOptions{all: true, wrap: []string{"time", "xargs"}}); err != nil {
+		t.Fatal(err)
+	}
+	out = buf.String()
+	if !strings.Contains(out, "function xargs\n    __reflag_wrap xargs \"$REFLAG_DISABLE_XARGS\" \"ls2eza\" $argv\nend\n") {
+		t.Errorf("missing fish xargs wrapper in:\n%s", out)
+	}
+	if strings.Contains(out, "function time") {
+		t.Errorf("fish should not get a time wrapper:\n%s", out)
+	}
+
+	if err := printInit(&bytes.Buffer{}, "nu", nil, initOptions{wrap: []string{"sudo"}}); err == nil {
+		t.Error("--wrap should be rejected for nu")
+	}
+	if _, _, err := parseInitOptions([]string{"--wrap=sudo,doas"}); err == nil {
+		t.Error("--wrap=doas should be rejected")
+	}
+}
+
+// TestPOSIXWrapRoundTrip runs prefix wrappers through each shell with fake
+// sudo and xargs binaries that print the argv they receive
+func TestPOSIXWrapRoundTrip(t *testing.T) {
+	bin := fakeBin(t, "reflag", "sudo", "xargs", "time")
+	opts := initOptions{all: true, wrap: prefixNames()}
+
+	tests := []struct {
+		script string
+		argv   []string
+		want   []string
+	}{
+		{`sudo "$@"`, []string{"-u", "bob", "grep", "-i", "it's here"}, []string{"-u", "bob", "rg", "-i", "it's here"}},
+		{`xargs "$@"`, []string{"-0", "-I{}", "grep", "-l", "x", "{}"}, []string{"-0", "-I{}", "rg", "-l", "x", "{}"}},
+		{`REFLAG_DISABLE_SUDO=1 sudo "$@"`, []string{"grep", "-i", "x"}, []string{"grep", "-i", "x"}},
+	}
+
+	for _, sh := range posixShells {
+		for _, tt := range tests {
+			t.Run(sh.name, func(t *testing.T) {
+				got, _ := runShInitOpts(t, sh.cmd, sh.init, opts, bin, tt.script, tt.argv)
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("%s under %s: got %q, want %q", tt.script, sh.name, got, tt.want)
+				}
+			})
+		}
+	}
+}
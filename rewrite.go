@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kluzzebass/reflag/cmdline"
+)
+
+var assignmentRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// rewriteLine translates the first command of a shell command line with
+// cmdline.TranslateLine. Lines it can't translate (no command, a command
+// none of names handles, one a routing rule keeps, a grade below the minimum
+// fidelity, arguments from expansions) are returned unchanged; only lines
+// that can't be split are an error.
+func rewriteLine(line string, names []string, mode string) (string, error) {
+	if len(names) == 0 {
+		return line, nil
+	}
+	// The line runs at an interactive prompt, so its output is taken to go
+	// to the terminal
+	ctx := currentContext()
+	ctx.tty = true
+	out, err := cmdline.TranslateLine(line, cmdline.Options{
+		Mode:        mode,
+		Translators: names,
+		MinFidelity: minFidelity(),
+		Skip: func(tool string, args []string) bool {
+			return userConfig().route(tool, args, ctx) != nil
+		},
+	})
+	var se *cmdline.SyntaxError
+	if errors.As(err, &se) {
+		return "", err
+	}
+	if err != nil {
+		return line, nil
+	}
+	return out, nil
+}
+
+// runRewrite implements --rewrite [--mode=MODE] [--translators=a,b] [--] LINE
+// and prints the rewritten line
+func runRewrite(args []string) {
+	mode := ""
+	names := selectTranslators(nil)
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		if after, ok := strings.CutPrefix(arg, "--mode="); ok {
+			mode = after
+		} else if after, ok := strings.CutPrefix(arg, "--translators="); ok {
+			names = selectTranslators(splitList(after))
+		} else {
+			fmt.Fprintf(os.Stderr, "error: unknown --rewrite option %q\n", arg)
+			os.Exit(exitError)
+		}
+	}
+
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: reflag --rewrite [--mode=MODE] [--translators=NAME,...] [--] LINE")
+		os.Exit(exitError)
+	}
+
+	out, err := rewriteLine(args[0], names, mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	fmt.Println(out)
+}
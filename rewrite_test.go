@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kluzzebass/reflag/cmdline"
+)
+
+func TestRewriteLine(t *testing.T) {
+	names := []string{"grep2rg", "ls2eza", "ps2procs"}
+	tests := []struct {
+		name     string
+		line     string
+		expected string
+	}{
+		{"simple", "ls -lt", "eza -l --sort=modified --reverse"},
+		{"pipeline keeps the rest", "ls -lt | head -3", "eza -l --sort=modified --reverse | head -3"},
+		{"only first command", "ls -a; ls -t", "eza -a; ls -t"},
+		{"leading whitespace", "  ls -a", "  eza -a"},
+		{"quoted operand keeps quoting", `ls -la "my dir"`, `eza -l -a "my dir"`},
+		{"glob stays unquoted", "ls -l *.go", "eza -l *.go"},
+		{"variable stays unquoted", "ls -a $HOME", "eza -a $HOME"},
+		{"assignment prefix", "LC_ALL=C ls -a", "LC_ALL=C eza -a"},
+		{"redirection", "ls -l > out.txt", "eza -l > out.txt"},
+		{"io number redirection", "ls -l 2>/dev/null", "eza -l 2>/dev/null"},
+		{"rewritten value is quoted", "grep --include=*.go foo .", "rg -g '*.go' foo ."},
+		{"unknown command", "git status", "git status"},
+		{"escaped command", `\ls -l`, `\ls -l`},
+		{"quoted command", `'ls' -l`, `'ls' -l`},
+		{"translator not selected", "find . -name x", "find . -name x"},
+		{"empty", "", ""},
+		{"rewritten expansion left alone", "grep --include=$EXT foo", "grep --include=$EXT foo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewriteLine(tt.line, names, "gnu")
+			if err != nil {
+				t.Fatalf("rewriteLine(%q) error: %v", tt.line, err)
+			}
+			if got != tt.expected {
+				t.Errorf("rewriteLine(%q) = %q, want %q", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRewriteLineErrors(t *testing.T) {
+	tests := []struct {
+		line   string
+		offset int
+	}{
+		{"ls 'unterminated", 3},
+		{`ls "unterminated`, 3},
+		{"ls $(date", 3},
+		{"ls `date", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			_, err := rewriteLine(tt.line, []string{"ls2eza"}, "")
+			var se *cmdline.SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("rewriteLine(%q) error = %v, want SyntaxError", tt.line, err)
+			}
+			if se.Offset != tt.offset {
+				t.Errorf("rewriteLine(%q) offset = %d, want %d", tt.line, se.Offset, tt.offset)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// ttyEnv is set by the shell wrappers to 1 or 0 for whether the command's
+// output goes to a terminal; reflag's own stdout is the wrapper's pipe
+const ttyEnv = "REFLAG_TTY"
+
+// rule routes matching commands to the source tool untranslated. A rule is
+// written as space-separated conditions, all of which must hold:
+//
+//	route = tool=ls flag=--dired
+//	route = tool=ps arg=^pid=$
+//	route = tool=grep cwd=~/src/legacy tty=no
+type rule struct {
+	text  string // as written
+	where string // file:line
+	tool  string
+	flags []string
+	args  []*regexp.Regexp
+	cwds  []string
+	tty   string // "", "yes" or "no"
+}
+
+// routeContext is what rules match besides the command
+type routeContext struct {
+	cwd string
+	tty bool
+
+	// known is false in the daemon, which sees neither the client's
+	// directory nor its terminal; cwd and tty conditions then match, so
+	// the request is left to a spawned reflag that can check them
+	known bool
+}
+
+// parseRule parses the conditions of a route setting
+func parseRule(text, where string) (rule, error) {
+	r := rule{text: strings.TrimSpace(text), where: where}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return r, fmt.Errorf("route has no conditions")
+	}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return r, fmt.Errorf("route condition %q is not key=value", field)
+		}
+		switch key {
+		case "tool":
+			if r.tool != "" {
+				return r, fmt.Errorf("route has more than one tool")
+			}
+			r.tool = value
+		case "flag":
+			if !strings.HasPrefix(value, "-") && !strings.HasPrefix(value, "+") {
+				return r, fmt.Errorf("route flag %q does not start with - or +", value)
+			}
+			r.flags = append(r.flags, value)
+		case "arg":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return r, fmt.Errorf("route arg: %v", err)
+			}
+			r.args = append(r.args, re)
+		case "cwd":
+			if _, err := filepath.Match(value, ""); err != nil {
+				return r, fmt.Errorf("route cwd %q: %v", value, err)
+			}
+			r.cwds = append(r.cwds, value)
+		case "tty":
+			if value != "yes" && value != "no" {
+				return r, fmt.Errorf("route tty must be yes or no, not %q", value)
+			}
+			r.tty = value
+		default:
+			return r, fmt.Errorf("unknown route condition %q", key)
+		}
+	}
+	return r, nil
+}
+
+// matches reports whether the rule applies to running tool with args
+func (r *rule) matches(tool string, args []string, ctx routeContext) bool {
+	if r.tool != "" && r.tool != tool {
+		return false
+	}
+	for _, flag := range r.flags {
+		if !hasFlag(args, flag) {
+			return false
+		}
+	}
+	for _, re := range r.args {
+		if !anyMatch(re, args) {
+			return false
+		}
+	}
+	if !ctx.known {
+		return true
+	}
+	for _, pattern := range r.cwds {
+		if !inDir(pattern, ctx.cwd) {
+			return false
+		}
+	}
+	return r.tty == "" || (r.tty == "yes") == ctx.tty
+}
+
+// hasFlag reports whether flag is given in args, as a word of its own
+// (find -delete), a long flag with a value (--color=never) or within a
+// bundle of short flags (-rP)
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return true
+		}
+	}
+	return len(translator.MapFlags(args, map[string]translator.Mapping{flag: {}})) > 0
+}
+
+func anyMatch(re *regexp.Regexp, args []string) bool {
+	for _, arg := range args {
+		if re.MatchString(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// inDir reports whether dir or one of its parents matches pattern; a
+// leading ~ stands for the home directory
+func inDir(pattern, dir string) bool {
+	if rest, ok := strings.CutPrefix(pattern, "~"); ok && (rest == "" || rest[0] == '/') {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		pattern = home + rest
+	}
+	pattern = filepath.Clean(pattern)
+	for dir != "" {
+		if ok, _ := filepath.Match(pattern, dir); ok {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return false
+}
+
+// currentContext returns the routing context of this process: the working
+// directory, and whether output goes to a terminal according to $REFLAG_TTY
+// or, when run by hand, stdout itself
+func currentContext() routeContext {
+	ctx := routeContext{known: true}
+	ctx.cwd, _ = os.Getwd()
+	switch os.Getenv(ttyEnv) {
+	case "1":
+		ctx.tty = true
+	case "0":
+	default:
+		if fi, err := os.Stdout.Stat(); err == nil {
+			ctx.tty = fi.Mode()&os.ModeCharDevice != 0
+		}
+	}
+	return ctx
+}
+
+// route returns the first of c's rules sending tool with args to the
+// native tool, or nil
+func (c *config) route(tool string, args []string, ctx routeContext) *rule {
+	for i := range c.rules {
+		if c.rules[i].matches(tool, args, ctx) {
+			return &c.rules[i]
+		}
+	}
+	return nil
+}
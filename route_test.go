@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// mustRules parses route settings, one per line
+func mustRules(t *testing.T, lines ...string) *config {
+	t.Helper()
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("route = " + line + "\n")
+	}
+	c, err := parseConfig(strings.NewReader(b.String()), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"tool",
+		"tool=ls tool=grep",
+		"flag=dired",
+		"arg=(",
+		"cwd=[",
+		"tty=maybe",
+		"user=root",
+	} {
+		if _, err := parseRule(text, "test:1"); err == nil {
+			t.Errorf("parseRule(%q) should fail", text)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	c := mustRules(t,
+		"tool=ls flag=--dired",
+		"tool=grep flag=-P",
+		"tool=ps flag=-o arg=^pid=$",
+		"tool=find flag=-delete",
+		"tool=du cwd=~/mnt/*",
+		"tool=df tty=no",
+	)
+	home := routeContext{cwd: "/home/me", tty: true, known: true}
+
+	tests := []struct {
+		name string
+		tool string
+		args []string
+		ctx  routeContext
+		want string // the rule that fires, "" for none
+	}{
+		{"long flag", "ls", []string{"-l", "--dired"}, home, "tool=ls flag=--dired"},
+		{"other tool", "grep", []string{"--dired"}, home, ""},
+		{"bundled short flag", "grep", []string{"-rP", "x"}, home, "tool=grep flag=-P"},
+		{"flag missing", "grep", []string{"-r", "x"}, home, ""},
+		{"after double dash", "grep", []string{"--", "-P"}, home, ""},
+		{"flag and arg", "ps", []string{"-o", "pid=", "-p", "42"}, home, "tool=ps flag=-o arg=^pid=$"},
+		{"arg mismatch", "ps", []string{"-o", "pid,comm"}, home, ""},
+		{"find word", "find", []string{".", "-name", "*.o", "-delete"}, home, "tool=find flag=-delete"},
+		{"cwd subdirectory", "du", []string{"-s"}, routeContext{cwd: "/home/me/mnt/nas/photos", known: true}, "tool=du cwd=~/mnt/*"},
+		{"cwd elsewhere", "du", []string{"-s"}, home, ""},
+		{"not a tty", "df", []string{"-h"}, routeContext{cwd: "/", known: true}, "tool=df tty=no"},
+		{"tty", "df", []string{"-h"}, home, ""},
+		{"unknown context", "df", []string{"-h"}, routeContext{}, "tool=df tty=no"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if r := c.route(tt.tool, tt.args, tt.ctx); r != nil {
+				got = r.text
+			}
+			if got != tt.want {
+				t.Errorf("route(%s %q) = %q, want %q", tt.tool, tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintExplain(t *testing.T) {
+	c := mustRules(t, "tool=find flag=-delete")
+	find := translator.GetByName("find2fd")
+	ctx := routeContext{known: true}
+
+	var b bytes.Buffer
+	printExplain(&b, c, find, []string{".", "-atime", "-1", "-delete"}, "", translator.Lossy, pipeAlways, ctx)
+	for _, want := range []string{
+		"fidelity: lossy\n",
+		"-atime",
+		"approximate",
+		"rule:     tool=find flag=-delete (test:1)\n",
+		"result:   find . -atime -1 -delete (routed by rule)\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("explain output missing %q:\n%s", want, b.String())
+		}
+	}
+
+	b.Reset()
+	printExplain(&b, c, find, []string{".", "-name", "*.go"}, "", translator.Exact, pipeAlways, ctx)
+	if want := "result:   fd '\\.go$'\n"; !strings.Contains(b.String(), want) {
+		t.Errorf("explain output missing %q:\n%s", want, b.String())
+	}
+}
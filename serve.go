@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// Limits on a single message, so a misbehaving client can't make the daemon
+// allocate without bound
+const (
+	maxMessageArgs = 4096
+	maxArgLen      = 1 << 20
+)
+
+// Messages between reflag serve and its clients are argv arrays: the
+// decimal element count and a colon, then each element as a netstring
+// (decimal length, colon, bytes, comma). ["ls", "-l"] is "2:2:ls,2:-l,".
+// Plain ASCII lengths keep the format easy to produce and parse from zsh.
+//
+// A request is [env..., mode, source, target, args...], where env holds the
+// client's values of the variables in serveEnv. The reply starts with an
+// exit status: "0" followed by the translated argv, exitRefused when there is
+// no translator (or the request is for reflag's own --version output), or
+// exitError for a malformed request.
+
+// serveEnv lists the environment variables that change how a command is
+// translated. The daemon refuses requests from clients whose values differ
+// from its own, leaving them to a spawned reflag that sees the client's
+// environment; an empty value counts as unset.
+var serveEnv = []string{"PATH", "HOME", "XDG_CONFIG_HOME", "REFLAG_CONFIG", minFidelityEnv, pipeEnv, validateEnv}
+
+// writeMessage writes argv in the wire format
+func writeMessage(w io.Writer, argv []string) error {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(len(argv)))
+	b.WriteByte(':')
+	for _, arg := range argv {
+		b.WriteString(strconv.Itoa(len(arg)))
+		b.WriteByte(':')
+		b.WriteString(arg)
+		b.WriteByte(',')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// readMessage reads one argv array in the wire format
+func readMessage(r *bufio.Reader) ([]string, error) {
+	n, err := readLength(r, maxMessageArgs)
+	if err != nil {
+		return nil, err
+	}
+	argv := make([]string, 0, n)
+	for range n {
+		size, err := readLength(r, maxArgLen)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+1)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[size] != ',' {
+			return nil, errors.New("malformed message: missing ','")
+		}
+		argv = append(argv, string(buf[:size]))
+	}
+	return argv, nil
+}
+
+// readLength reads a decimal number terminated by ':'
+func readLength(r *bufio.Reader, limit int) (int, error) {
+	s, err := r.ReadString(':')
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(s, ":"))
+	if err != nil || n < 0 || n > limit {
+		return 0, fmt.Errorf("malformed message: bad length %q", s)
+	}
+	return n, nil
+}
+
+// defaultSocketPath returns the per-user socket location:
+// $XDG_RUNTIME_DIR/reflag.sock, or reflag-<uid>/reflag.sock in the temp directory
+func defaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "reflag.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("reflag-%d", os.Getuid()), "reflag.sock")
+}
+
+// socketPath returns $REFLAG_SOCKET, or the default per-user socket
+func socketPath() string {
+	if p := os.Getenv("REFLAG_SOCKET"); p != "" {
+		return p
+	}
+	return defaultSocketPath()
+}
+
+// handleRequest answers one translation request. Translators are stateless
+// and looked up through the registry's read lock, so requests can be
+// answered concurrently.
+func handleRequest(req []string) []string {
+	if len(req) < len(serveEnv)+3 {
+		return []string{strconv.Itoa(exitError)}
+	}
+	for i, name := range serveEnv {
+		if req[i] != os.Getenv(name) {
+			return []string{strconv.Itoa(exitRefused)}
+		}
+	}
+	req = req[len(serveEnv):]
+	mode, source, target, args := req[0], req[1], req[2], req[3:]
+	t := translator.Get(source, target)
+	if t == nil {
+		return []string{strconv.Itoa(exitRefused)}
+	}
+	// reflag prints its own version for these; leave that to the spawned path
+	for _, arg := range args {
+		if arg == "-V" || arg == "--version" {
+			return []string{strconv.Itoa(exitRefused)}
+		}
+	}
+	// The spawned reflag prints the original command for these. Rules and
+	// pipe policies that depend on the client's directory or terminal are
+	// left to it as well.
+	if userConfig().route(source, args, routeContext{}) != nil || belowMinimum(t, args, mode, minFidelity()) ||
+		pipePolicy(t.Name()) != pipeAlways {
+		return []string{strconv.Itoa(exitRefused)}
+	}
+	return append([]string{"0", t.TargetTool()}, validateArgs(t.SourceTool(), t.TargetTool(), t.Translate(args, mode), validatePolicy())...)
+}
+
+// serveConn answers a single request on conn
+func serveConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, err := readMessage(bufio.NewReader(conn))
+	if err != nil {
+		writeMessage(conn, []string{strconv.Itoa(exitError)})
+		return
+	}
+	writeMessage(conn, handleRequest(req))
+}
+
+// serve accepts connections until ln is closed
+func serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go serveConn(conn)
+	}
+}
+
+// checkSocketDir makes sure only the current user can reach dir. The
+// default /tmp/reflag-<uid> is predictable, so another user could create it
+// first and serve translations that the wrappers then run.
+func checkSocketDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || !ownedByUser(info) || info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("%s must be a directory owned by you with mode 0700", dir)
+	}
+	return nil
+}
+
+// listenSocket listens on path, readable only by the current user. A socket
+// left behind by a daemon that died is replaced; a live one is an error.
+func listenSocket(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.Mkdir(dir, 0o700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, err
+	}
+	if err := checkSocketDir(dir); err != nil {
+		return nil, err
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("reflag serve is already running on %s", path)
+	}
+	os.Remove(path)
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// queryServer sends one request to the daemon at path and returns its reply
+func queryServer(path string, req []string) ([]string, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if err := writeMessage(conn, req); err != nil {
+		return nil, err
+	}
+	return readMessage(bufio.NewReader(conn))
+}
+
+// runServe implements serve [--socket=PATH] and runs until interrupted
+func runServe(args []string) {
+	path := socketPath()
+	for _, arg := range args {
+		if after, ok := strings.CutPrefix(arg, "--socket="); ok {
+			path = after
+		} else {
+			fmt.Fprintf(os.Stderr, "error: unknown serve option %q\n", arg)
+			os.Exit(exitError)
+		}
+	}
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+	defer os.Remove(path)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "reflag: serving on %s\n", path)
+	if err := serve(ln); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(exitError)
+	}
+}
+
+// zshClient lets zsh wrappers ask a running reflag serve for translations
+// without forking, using zsh's socket and sysread/syswrite modules. It
+// replaces __reflag_run with a version that tries the daemon first and keeps
+// the original (spawning reflag) as the fallback, so anything the daemon
+// can't answer behaves exactly as before. Other shells have no way to talk
+// to a unix socket without starting a process, so they always spawn reflag.
+// A socket or directory owned by someone else is ignored, since the client
+// runs whatever argv comes back. no_multibyte makes ${#a} and subscripts
+// count bytes, as the format does. The verbs take the default socket path
+// and zshClientEnv.
+const zshClient = `if zmodload zsh/net/socket zsh/system 2>/dev/null; then
+    __reflag_sock=${REFLAG_SOCKET:-%s}
+    functions[__reflag_spawn_run]=$functions[__reflag_run]
+
+    __reflag_client() {
+        emulate -L zsh
+        setopt no_multibyte
+        [[ -S $__reflag_sock && -O $__reflag_sock && -O ${__reflag_sock:h} ]] || return 1
+        zsocket $__reflag_sock 2>/dev/null || return 1
+        local fd=$REPLY msg="$#:" a resp buf
+        local -i n len
+        for a in "$@"; do msg+="${#a}:$a,"; done
+        syswrite -o $fd -- $msg
+        while sysread -i $fd buf; do resp+=$buf; done
+        exec {fd}>&-
+        [[ $resp == <->:* ]] || return 1
+        n=${resp%%%%:*}
+        resp=${resp#*:}
+        __reflag_argv=()
+        while (( n-- > 0 )); do
+            [[ $resp == <->:* ]] || return 1
+            len=${resp%%%%:*}
+            resp=${resp#*:}
+            __reflag_argv+=("${resp[1,len]}")
+            resp=${resp[len+2,-1]}
+        done
+        [[ ${__reflag_argv[1]-} == 0 ]] && (( ${#__reflag_argv} > 1 ))
+    }
+
+    __reflag_run() {
+        if ! __reflag_off "${REFLAG_DISABLE-}" && ! __reflag_off "$3" &&
+            __reflag_client %s '' "$1" "$2" "${@:4}"; then
+            command "${(@)__reflag_argv[2,-1]}"
+            return
+        fi
+        __reflag_spawn_run "$@"
+    }
+fi
+`
+
+// zshClientEnv returns the words that expand to the client's serveEnv values
+func zshClientEnv() string {
+	words := make([]string, len(serveEnv))
+	for i, name := range serveEnv {
+		words[i] = `"${` + name + `-}"`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMessageRoundTrip(t *testing.T) {
+	tests := [][]string{
+		{},
+		{""},
+		{"ls", "-l"},
+		{"grep", "a:b,c", "", "naïve", "new\nline", "12:34,"},
+	}
+
+	for _, argv := range tests {
+		var buf bytes.Buffer
+		if err := writeMessage(&buf, argv); err != nil {
+			t.Fatal(err)
+		}
+		got, err := readMessage(bufio.NewReader(&buf))
+		if err != nil {
+			t.Fatalf("readMessage(%q): %v", buf.String(), err)
+		}
+		if !slices.Equal(got, argv) {
+			t.Errorf("round trip = %q, want %q", got, argv)
+		}
+	}
+
+	var buf bytes.Buffer
+	writeMessage(&buf, []string{"ls", "-l"})
+	if want := "2:2:ls,2:-l,"; buf.String() != want {
+		t.Errorf("encoding = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReadMessageMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"x:",
+		"-1:",
+		"1:3:abc;",
+		"2:2:ls,",
+		"1:99999999:",
+		fmt.Sprintf("%d:", maxMessageArgs+1),
+	}
+
+	for _, input := range tests {
+		if got, err := readMessage(bufio.NewReader(strings.NewReader(input))); err == nil {
+			t.Errorf("readMessage(%q) = %q, want error", input, got)
+		}
+	}
+}
+
+// request builds a request for handleRequest carrying this process's own
+// values of serveEnv, as a client sharing the daemon's settings would send
+func request(argv ...string) []string {
+	var req []string
+	for _, name := range serveEnv {
+		req = append(req, os.Getenv(name))
+	}
+	return append(req, argv...)
+}
+
+func TestHandleRequest(t *testing.T) {
+	tests := []struct {
+		req  []string
+		want []string
+	}{
+		{request("", "grep", "rg", "-i", "foo"), []string{"0", "rg", "-i", "foo"}},
+		{request("gnu", "ls", "eza", "-a"), []string{"0", "eza", "-a"}},
+		{request("", "ls", "nosuchtool", "-l"), []string{"3"}},
+		{request("", "grep", "rg", "--version"), []string{"3"}},
+		{request("", "grep"), []string{"1"}},
+		{[]string{"", "grep", "rg", "-i", "foo"}, []string{"1"}},
+	}
+
+	for _, tt := range tests {
+		if got := handleRequest(tt.req); !slices.Equal(got, tt.want) {
+			t.Errorf("handleRequest(%q) = %q, want %q", tt.req, got, tt.want)
+		}
+	}
+}
+
+// TestHandleRequestSettings checks the daemon leaves clients with other
+// settings to a spawned reflag and follows edits to the config file
+func TestHandleRequestSettings(t *testing.T) {
+	req := request("", "grep", "rg", "-s", "foo")
+	req[slices.Index(serveEnv, minFidelityEnv)] = "exact"
+	if got := handleRequest(req); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("request with another %s = %q, want refusal", minFidelityEnv, got)
+	}
+
+	config := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(config, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("REFLAG_CONFIG", config)
+	if got := handleRequest(request("", "grep", "rg", "-s", "foo")); !slices.Equal(got, []string{"0", "rg", "-s", "foo"}) {
+		t.Errorf("handleRequest = %q, want a translation", got)
+	}
+	if err := os.WriteFile(config, []byte("min-fidelity = exact\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := handleRequest(request("", "grep", "rg", "-s", "foo")); !slices.Equal(got, []string{"3"}) {
+		t.Errorf("handleRequest after editing the config = %q, want refusal", got)
+	}
+}
+
+// TestServeConcurrentClients hammers the daemon from many goroutines; run
+// with -race to check the registry and translators are safe to share
+func TestServeConcurrentClients(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, too short for t.TempDir
+	dir, err := os.MkdirTemp("", "reflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "run", "reflag.sock")
+
+	ln, err := listenSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- serve(ln) }()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("socket mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := listenSocket(path); err == nil {
+		t.Error("second listener on a live socket should fail")
+	}
+
+	requests := []struct {
+		req  []string
+		want []string
+	}{
+		{request("", "grep", "rg", "-rni", "TODO"), handleRequest(request("", "grep", "rg", "-rni", "TODO"))},
+		{request("", "ls", "eza", "-ltr"), handleRequest(request("", "ls", "eza", "-ltr"))},
+		{request("", "du", "dust", "-sh"), handleRequest(request("", "du", "dust", "-sh"))},
+		{request("", "find", "fd", ".", "-name", "*.go"), handleRequest(request("", "find", "fd", ".", "-name", "*.go"))},
+	}
+
+	var wg sync.WaitGroup
+	for i := range 64 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := requests[i%len(requests)]
+			for range 10 {
+				got, err := queryServer(path, r.req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if !slices.Equal(got, r.want) {
+					t.Errorf("reply to %q = %q, want %q", r.req, got, r.want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	ln.Close()
+	if err := <-done; err != nil {
+		t.Errorf("serve returned %v after close", err)
+	}
+
+	// A socket left behind by a dead daemon is replaced
+	ln, err = listenSocket(path)
+	if err != nil {
+		t.Fatalf("stale socket not replaced: %v", err)
+	}
+	ln.Close()
+}
+
+// TestListenSocketUnsafeDir checks the daemon won't serve from a directory
+// other users can reach or own, such as a squatted /tmp/reflag-<uid>
+func TestListenSocketUnsafeDir(t *testing.T) {
+	dir, err := os.MkdirTemp("", "reflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	open := filepath.Join(dir, "open")
+	if err := os.Mkdir(open, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	os.Chmod(open, 0o777)
+	if ln, err := listenSocket(filepath.Join(open, "reflag.sock")); err == nil {
+		ln.Close()
+		t.Error("listenSocket should refuse a directory others can write to")
+	}
+
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatal(err)
+	}
+	if ln, err := listenSocket(filepath.Join(link, "reflag.sock")); err == nil {
+		ln.Close()
+		t.Error("listenSocket should refuse a symlinked directory")
+	}
+
+	// Only root can hand a directory to another user
+	if os.Getuid() == 0 {
+		other := filepath.Join(dir, "other")
+		if err := os.Mkdir(other, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chown(other, 65534, 65534); err != nil {
+			t.Fatal(err)
+		}
+		if ln, err := listenSocket(filepath.Join(other, "reflag.sock")); err == nil {
+			ln.Close()
+			t.Error("listenSocket should refuse a directory owned by another user")
+		}
+	}
+}
+
+// TestZshClientSettings runs the zsh client against a daemon sharing its
+// settings, then with a setting of its own, which must go to a spawned reflag
+func TestZshClientSettings(t *testing.T) {
+	zsh, err := exec.LookPath("zsh")
+	if err != nil {
+		t.Skip("zsh not installed")
+	}
+	dir, err := os.MkdirTemp("", "reflag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	// The shell tests run with only PATH and HOME set. Without reflag on
+	// PATH, only the daemon can translate.
+	bin := fakeBin(t, "grep", "rg")
+	for _, name := range serveEnv {
+		t.Setenv(name, "")
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("HOME", "/nonexistent")
+
+	ln, err := listenSocket(defaultSocketPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go serve(ln)
+
+	shell := []string{zsh}
+	if got, _ := runShInit(t, shell, "zsh", bin, `grep "$@"`, []string{"-rs", "foo"}); !slices.Equal(got, []string{"-s", "foo"}) {
+		t.Errorf("argv = %q, want the daemon's translation", got)
+	}
+	got, _ := runShInit(t, shell, "zsh", bin, `export REFLAG_MIN_FIDELITY=exact; grep "$@"`, []string{"-rs", "foo"})
+	if !slices.Equal(got, []string{"-rs", "foo"}) {
+		t.Errorf("argv = %q, want grep's own with REFLAG_MIN_FIDELITY=exact", got)
+	}
+}
+
+func TestPrintInitZshClient(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+	var buf bytes.Buffer
+	printInit(&buf, "zsh", nil, initOptions{all: true})
+	out := buf.String()
+	for _, want := range []string{
+		"zmodload zsh/net/socket zsh/system",
+		"__reflag_sock=${REFLAG_SOCKET:-/run/user/1000/reflag.sock}\n",
+		"functions[__reflag_spawn_run]=$functions[__reflag_run]\n",
+		"n=${resp%%:*}\n",
+		"-O $__reflag_sock && -O ${__reflag_sock:h} ]] || return 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	printInit(&buf, "bash", nil, initOptions{all: true})
+	if strings.Contains(buf.String(), "zsocket") {
+		t.Error("bash init should not include the zsh socket client")
+	}
+}
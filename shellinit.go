@@ -0,0 +1,425 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// initShells lists the shells --init can generate wrappers for
+var initShells = map[string]bool{
+	"bash":   true,
+	"zsh":    true,
+	"sh":     true,
+	"ksh":    true,
+	"fish":   true,
+	"nu":     true,
+	"elvish": true,
+	"xonsh":  true,
+}
+
+// parseInitArgs parses --init arguments, returning the shell type and the
+// translator selection expressions (see selectTranslators)
+// Shell can appear anywhere in args; defaults to "bash" if not specified
+func parseInitArgs(args []string) (shell string, selection []string) {
+	shell = "bash"
+	for _, arg := range args {
+		if initShells[arg] {
+			shell = arg
+		} else {
+			selection = append(selection, arg)
+		}
+	}
+	return
+}
+
+// initOptions holds --init options that change what kind of code is generated
+type initOptions struct {
+	widget string   // "" for wrapper functions, "key" or "enter" for a line-rewriting widget
+	all    bool     // include translators whose target tool isn't installed
+	wrap   []string // prefix commands (sudo, xargs, ...) to wrap as well
+
+	// portable emits self-contained sh functions compiled from flag tables
+	portable bool
+}
+
+// parseInitOptions extracts --option arguments from --init arguments,
+// returning the remaining arguments for parseInitArgs
+func parseInitOptions(args []string) (opts initOptions, rest []string, err error) {
+	for _, arg := range args {
+		switch {
+		case arg == "--widget" || arg == "--widget=key":
+			opts.widget = "key"
+		case arg == "--widget=enter":
+			opts.widget = "enter"
+		case arg == "--all":
+			opts.all = true
+		case arg == "--portable":
+			opts.portable = true
+		case arg == "--wrap":
+			opts.wrap = prefixNames()
+		case strings.HasPrefix(arg, "--wrap="):
+			opts.wrap = splitList(strings.TrimPrefix(arg, "--wrap="))
+			for _, name := range opts.wrap {
+				if prefixCommands[name] == nil {
+					return opts, nil, fmt.Errorf("unknown prefix command %q for --wrap (supported: %s)", name, strings.Join(prefixNames(), ", "))
+				}
+			}
+		case strings.HasPrefix(arg, "--"):
+			return opts, nil, fmt.Errorf("unknown --init option %q", arg)
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return opts, rest, nil
+}
+
+// selectTranslators resolves selection expressions (translator names,
+// globs such as *2moor, tags, each optionally prefixed with + or -) against
+// the default translators and returns the sorted names. The config file's
+// translators setting comes first, unless the command line starts with a
+// bare term and so replaces the selection outright.
+func selectTranslators(selection []string) []string {
+	if len(selection) == 0 || translator.IsModifier(selection[0]) {
+		selection = append(slices.Clone(userConfig().translators), selection...)
+	}
+	names, err := translator.Select(translator.Match("default"), selection)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return names
+}
+
+func printInit(w io.Writer, shell string, selection []string, opts initOptions) error {
+	names := selectTranslators(selection)
+	if err := checkPortable(shell, opts); err != nil {
+		return err
+	}
+	if opts.portable {
+		// The host it runs on decides which targets are installed
+		writePortableInit(w, names)
+		return nil
+	}
+	if !opts.all {
+		var skipped []string
+		names, skipped = installedTranslators(names)
+		writeSkipped(w, skipped)
+	}
+
+	if err := checkWrap(shell, opts); err != nil {
+		return err
+	}
+	if opts.widget != "" {
+		return writeWidgetInit(w, shell, names, opts.widget)
+	}
+
+	switch shell {
+	case "fish":
+		writeFishInit(w, names)
+		writeFishPrefixWrappers(w, opts.wrap, names)
+	case "nu":
+		writeNuInit(w, names)
+	case "elvish":
+		writeElvishInit(w, names)
+	case "xonsh":
+		writeXonshInit(w, names)
+	default: // bash, zsh, sh, ksh
+		writeShInit(w, shell, names)
+		writeShPrefixWrappers(w, opts.wrap, names)
+	}
+	return nil
+}
+
+// installedTranslators splits names into translators whose target tool is
+// on PATH and the rest, so shared dotfiles don't wrap less or screen on
+// hosts without moor or tmux
+func installedTranslators(names []string) (installed, skipped []string) {
+	for _, name := range names {
+		if _, err := exec.LookPath(translator.GetByName(name).TargetTool()); err != nil {
+			skipped = append(skipped, name)
+		} else {
+			installed = append(installed, name)
+		}
+	}
+	return installed, skipped
+}
+
+// writeSkipped emits a comment naming the translators installedTranslators
+// left out. Every supported shell uses # for comments.
+func writeSkipped(w io.Writer, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "# Skipped because the target tool is not on PATH (use --all to include):")
+	for _, name := range skipped {
+		fmt.Fprintf(w, "#   %s: %s not found\n", name, translator.GetByName(name).TargetTool())
+	}
+	fmt.Fprintln(w)
+}
+
+// shRunner holds the helpers every sh-family wrapper calls. __reflag_run
+// takes the source and target tool and the value of the translator's
+// REFLAG_DISABLE_<NAME> variable, then the arguments. It runs the source tool
+// directly when translation is switched off, and falls back to it when reflag
+// fails or prints nothing, warning only when the failure wasn't a deliberate
+// refusal (exitRefused). REFLAG_TTY tells reflag whether the command's
+// output goes to a terminal, as reflag's own stdout is the command
+// substitution's pipe. The reflag function lets reflag on/off/pause change
+// the current shell's environment. Everything sticks to POSIX sh so the same
+// code loads in bash, zsh, dash, ksh, mksh and busybox ash; reflag is run via
+// command so a wrapper can never call itself.
+const shRunner = `__reflag_off() {
+    case $1 in
+        ''|0) return 1 ;;
+        until:*) [ "$(date +%s)" -lt "${1#until:}" ] ;;
+        *) return 0 ;;
+    esac
+}
+
+__reflag_run() {
+    __reflag_src=$1
+    __reflag_tgt=$2
+    if __reflag_off "${REFLAG_DISABLE-}" || __reflag_off "$3"; then
+        shift 3
+        command "$__reflag_src" "$@"
+        return
+    fi
+    shift 3
+    [ -t 1 ] && __reflag_tty=1 || __reflag_tty=0
+    __reflag_out=$(REFLAG_TTY=$__reflag_tty command reflag "$__reflag_src" "$__reflag_tgt" "$@")
+    __reflag_status=$?
+    if [ "$__reflag_status" -ne 0 ] || [ -z "$__reflag_out" ]; then
+        if [ "$__reflag_status" -ne 3 ]; then
+            echo "reflag: translation failed (exit $__reflag_status), running $__reflag_src directly" >&2
+        fi
+        command "$__reflag_src" "$@"
+        return
+    fi
+    eval "$__reflag_out"
+}
+
+reflag() {
+    case ${1-} in
+        on|off|pause)
+            __reflag_out=$(command reflag "$@") && eval "$__reflag_out"
+            ;;
+        *)
+            command reflag "$@"
+            ;;
+    esac
+}
+`
+
+// fishRunner is the fish counterpart of shRunner
+const fishRunner = `function __reflag_off
+    switch "$argv[1]"
+        case '' 0
+            return 1
+        case 'until:*'
+            test (date +%s) -lt (string replace until: '' -- $argv[1])
+        case '*'
+            return 0
+    end
+end
+
+function __reflag_run
+    set -l src $argv[1]
+    if __reflag_off "$REFLAG_DISABLE"; or __reflag_off "$argv[3]"
+        command $src $argv[4..-1]
+        return
+    end
+    set -lx REFLAG_TTY 0
+    isatty stdout; and set REFLAG_TTY 1
+    set -l out (command reflag $argv[1..2] $argv[4..-1])
+    set -l st $status
+    if test $st -ne 0; or test -z "$out"
+        if test $st -ne 3
+            echo "reflag: translation failed (exit $st), running $src directly" >&2
+        end
+        command $src $argv[4..-1]
+        return
+    end
+    eval $out
+end
+
+function reflag
+    switch "$argv[1]"
+        case on off pause
+            set -l out (command reflag $argv --shell=fish); and eval (string join \n -- $out)
+        case '*'
+            command reflag $argv
+    end
+end
+`
+
+// writeShInit emits wrappers for bash, zsh and the POSIX shells. An alias
+// for the source tool is saved for --deinit before it is removed; unalias
+// fails when no alias exists, which would abort a set -e shell, so its status
+// is discarded.
+func writeShInit(w io.Writer, shell string, names []string) {
+	switch shell {
+	case "sh":
+		fmt.Fprintln(w, "# reflag shell init - add to the file named by $ENV (e.g. ~/.shrc)")
+	case "ksh":
+		fmt.Fprintln(w, "# reflag shell init - add to your ~/.kshrc (or the file named by $ENV)")
+	default:
+		fmt.Fprintln(w, "# reflag shell init - add to your ~/.bashrc or ~/.zshrc")
+	}
+	fmt.Fprintln(w)
+	io.WriteString(w, shRunner)
+	fmt.Fprintln(w)
+	if shell == "zsh" {
+		fmt.Fprintln(w, "# Use a running 'reflag serve' when there is one")
+		fmt.Fprintf(w, zshClient, shellQuote(defaultSocketPath()), zshClientEnv())
+		fmt.Fprintln(w)
+	}
+	for _, name := range names {
+		t := translator.GetByName(name)
+		fmt.Fprintf(w, "__reflag_a=$(alias %s 2>/dev/null) && %s=$__reflag_a\n", t.SourceTool(), savedAliasVar(t.SourceTool()))
+		fmt.Fprintf(w, "unalias %s 2>/dev/null || :\n", t.SourceTool())
+		fmt.Fprintf(w, "%s() {\n", t.SourceTool())
+		fmt.Fprintf(w, "    __reflag_run %s %s \"${%s-}\" \"$@\"\n", t.SourceTool(), t.TargetTool(), disableEnvFor(name))
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+func writeFishInit(w io.Writer, names []string) {
+	fmt.Fprintln(w, "# reflag shell init - add to your ~/.config/fish/config.fish")
+	fmt.Fprintln(w)
+	io.WriteString(w, fishRunner)
+	fmt.Fprintln(w)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		writeFishSaveFunction(w, t.SourceTool())
+		fmt.Fprintf(w, "functions -e %s 2>/dev/null\n", t.SourceTool())
+		fmt.Fprintf(w, "function %s\n", t.SourceTool())
+		fmt.Fprintf(w, "    __reflag_run %s %s \"$%s\" $argv\n", t.SourceTool(), t.TargetTool(), disableEnvFor(name))
+		fmt.Fprintln(w, "end")
+		fmt.Fprintln(w)
+	}
+}
+
+// writeNuInit emits nushell wrappers. nushell has no eval, so the wrappers
+// ask reflag for a JSON argv and spread it into run-external.
+// --wrapped makes nushell pass flags through to the rest parameter untouched.
+func writeNuInit(w io.Writer, names []string) {
+	fmt.Fprintln(w, "# reflag shell init - save to a file and source it from your config.nu:")
+	fmt.Fprintln(w, "#   reflag --init nu | save -f ~/.config/nushell/reflag.nu")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		fmt.Fprintf(w, "def --wrapped %s [...args] {\n", t.SourceTool())
+		fmt.Fprintf(w, "    let cmd = (^reflag --format=json %s %s ...$args | from json)\n", t.SourceTool(), t.TargetTool())
+		fmt.Fprintln(w, "    run-external ($cmd | first) ...($cmd | skip 1)")
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+// writeElvishInit emits elvish wrappers using from-json and list explosion
+func writeElvishInit(w io.Writer, names []string) {
+	fmt.Fprintln(w, "# reflag shell init - add to your ~/.config/elvish/rc.elv:")
+	fmt.Fprintln(w, "#   eval (reflag --init elvish | slurp)")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		fmt.Fprintf(w, "fn %s {|@args|\n", t.SourceTool())
+		fmt.Fprintf(w, "    var cmd = (e:reflag --format=json %s %s $@args | from-json)\n", t.SourceTool(), t.TargetTool())
+		fmt.Fprintln(w, "    (external $cmd[0]) (all $cmd[1..])")
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+	}
+}
+
+// writeXonshInit emits xonsh callable aliases that run the translated argv
+// with xonsh's @() list expansion
+func writeXonshInit(w io.Writer, names []string) {
+	fmt.Fprintln(w, "# reflag shell init - add to your ~/.xonshrc:")
+	fmt.Fprintln(w, "#   execx($(reflag --init xonsh))")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "import json as _reflag_json")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		t := translator.GetByName(name)
+		fn := "_reflag_" + strings.ReplaceAll(t.SourceTool(), "-", "_")
+		fmt.Fprintf(w, "def %s(args):\n", fn)
+		fmt.Fprintf(w, "    cmd = _reflag_json.loads($(reflag --format=json %s %s @(args)))\n", t.SourceTool(), t.TargetTool())
+		fmt.Fprintln(w, "    ![@(cmd)]")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "aliases[%q] = %s\n", t.SourceTool(), fn)
+		fmt.Fprintln(w)
+	}
+}
+
+// Key bound to the rewrite widget in every shell (Ctrl-X t)
+const widgetKeyHint = "Ctrl-X t"
+
+// writeWidgetInit emits a line editor widget that replaces the command line
+// with its translation, so history records the command that actually ran.
+// With mode "enter" the widget also runs whenever a line is accepted. A line
+// reflag can't parse, such as one with an unclosed quote, is left as it is
+// without an error message, which would otherwise be printed on every Enter.
+func writeWidgetInit(w io.Writer, shell string, names []string, mode string) error {
+	rewrite := fmt.Sprintf("command reflag --rewrite --translators=%s --", strings.Join(names, ","))
+
+	switch shell {
+	case "zsh":
+		fmt.Fprintln(w, "# reflag line rewriting widget - add to your ~/.zshrc")
+		fmt.Fprintf(w, "# Press %s to translate the command line in place\n", widgetKeyHint)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "__reflag_rewrite() {")
+		fmt.Fprintln(w, "    local __reflag_line")
+		fmt.Fprintf(w, "    __reflag_line=$(%s \"$BUFFER\" 2>/dev/null) || return 0\n", rewrite)
+		fmt.Fprintln(w, "    BUFFER=$__reflag_line")
+		fmt.Fprintln(w, "    CURSOR=${#BUFFER}")
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w, "zle -N __reflag_rewrite")
+		fmt.Fprintln(w, "bindkey '^Xt' __reflag_rewrite")
+		if mode == "enter" {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "__reflag_accept_line() {")
+			fmt.Fprintln(w, "    __reflag_rewrite")
+			fmt.Fprintln(w, "    zle .accept-line")
+			fmt.Fprintln(w, "}")
+			fmt.Fprintln(w, "zle -N accept-line __reflag_accept_line")
+		}
+	case "bash":
+		fmt.Fprintln(w, "# reflag line rewriting widget - add to your ~/.bashrc")
+		fmt.Fprintf(w, "# Press %s to translate the command line in place\n", widgetKeyHint)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "__reflag_rewrite() {")
+		fmt.Fprintln(w, "    local __reflag_line")
+		fmt.Fprintf(w, "    __reflag_line=$(%s \"$READLINE_LINE\" 2>/dev/null) || return 0\n", rewrite)
+		fmt.Fprintln(w, "    READLINE_LINE=$__reflag_line")
+		fmt.Fprintln(w, "    READLINE_POINT=${#READLINE_LINE}")
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w, `bind -x '"\C-xt": __reflag_rewrite'`)
+		if mode == "enter" {
+			// bind -x can't accept the line itself, so Enter becomes a macro
+			// that runs the widget and then accept-line (C-j)
+			fmt.Fprintln(w, `bind '"\C-m": "\C-xt\C-j"'`)
+		}
+	case "fish":
+		fmt.Fprintln(w, "# reflag line rewriting widget - add to your ~/.config/fish/config.fish")
+		fmt.Fprintf(w, "# Press %s to translate the command line in place\n", widgetKeyHint)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "function __reflag_rewrite")
+		fmt.Fprintf(w, "    set -l out (%s (string join \\n -- (commandline)) 2>/dev/null)\n", rewrite)
+		fmt.Fprintln(w, "    or return 0")
+		fmt.Fprintln(w, "    commandline -r -- (string join \\n -- $out)")
+		fmt.Fprintln(w, "end")
+		fmt.Fprintln(w, `bind \cxt __reflag_rewrite`)
+		if mode == "enter" {
+			fmt.Fprintln(w, `bind \r __reflag_rewrite execute`)
+		}
+	default:
+		return fmt.Errorf("line rewriting widgets are only available for bash, zsh and fish")
+	}
+	return nil
+}
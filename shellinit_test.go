@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseInitArgsShells(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "sh", "ksh", "fish", "nu", "elvish", "xonsh"} {
+		t.Run(shell, func(t *testing.T) {
+			got, _ := parseInitArgs([]string{shell})
+			if got != shell {
+				t.Errorf("parseInitArgs([%s]) shell = %q, want %q", shell, got, shell)
+			}
+		})
+	}
+}
+
+func TestPrintInit(t *testing.T) {
+	tests := []struct {
+		shell    string
+		contains []string
+	}{
+		{
+			shell: "bash",
+			contains: []string{
+				"__reflag_out=$(REFLAG_TTY=$__reflag_tty command reflag \"$__reflag_src\" \"$__reflag_tgt\" \"$@\")\n",
+				"command \"$__reflag_src\" \"$@\"\n",
+				"__reflag_a=$(alias ls 2>/dev/null) && __reflag_alias_ls=$__reflag_a\nunalias ls 2>/dev/null || :\n",
+				"ls() {\n    __reflag_run ls eza \"${REFLAG_DISABLE_LS2EZA-}\" \"$@\"\n}\n",
+				"reflag() {\n",
+			},
+		},
+		{
+			shell: "sh",
+			contains: []string{
+				"__reflag_run() {\n",
+				"unalias ls 2>/dev/null || :\n",
+				"ls() {\n    __reflag_run ls eza \"${REFLAG_DISABLE_LS2EZA-}\" \"$@\"\n}\n",
+			},
+		},
+		{
+			shell: "fish",
+			contains: []string{
+				"set -l out (command reflag $argv[1..2] $argv[4..-1])\n",
+				"command $src $argv[4..-1]\n",
+				"set -l out (command reflag $argv --shell=fish)",
+				"function ls\n    __reflag_run ls eza \"$REFLAG_DISABLE_LS2EZA\" $argv\nend\n",
+			},
+		},
+		{
+			shell: "nu",
+			contains: []string{
+				"def --wrapped ls [...args] {\n",
+				"let cmd = (^reflag --format=json ls eza ...$args | from json)\n",
+				"run-external ($cmd | first) ...($cmd | skip 1)\n",
+			},
+		},
+		{
+			shell: "elvish",
+			contains: []string{
+				"fn ls {|@args|\n",
+				"var cmd = (e:reflag --format=json ls eza $@args | from-json)\n",
+				"(external $cmd[0]) (all $cmd[1..])\n",
+			},
+		},
+		{
+			shell: "xonsh",
+			contains: []string{
+				"import json as _reflag_json\n",
+				"def _reflag_ls(args):\n",
+				"cmd = _reflag_json.loads($(reflag --format=json ls eza @(args)))\n",
+				"![@(cmd)]\n",
+				"aliases[\"ls\"] = _reflag_ls\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			printInit(&buf, tt.shell, nil, initOptions{all: true})
+			out := buf.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("printInit(%s) missing %q in:\n%s", tt.shell, want, out)
+				}
+			}
+		})
+	}
+}
+
+// TestMain lets the test binary stand in for both reflag and a target tool
+// when the POSIX round-trip test runs it through a symlink.
+func TestMain(m *testing.M) {
+	if os.Getenv("REFLAG_TEST_EXEC") == "1" {
+		if filepath.Base(os.Args[0]) == "reflag" {
+			main()
+			os.Exit(0)
+		}
+		out, _ := json.Marshal(os.Args[1:])
+		fmt.Println(string(out))
+		os.Exit(0)
+	}
+	// Keep the user's own config file and cache out of the tests
+	os.Setenv("REFLAG_CONFIG", os.DevNull)
+	cache, err := os.MkdirTemp("", "reflag-cache")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", cache)
+	code := m.Run()
+	os.RemoveAll(cache)
+	os.Exit(code)
+}
+
+// fakeBin returns a directory holding symlinks to the test binary under the
+// given names, for use as PATH in shell tests
+func fakeBin(t *testing.T, names ...string) string {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := t.TempDir()
+	for _, name := range names {
+		if err := os.Symlink(self, filepath.Join(bin, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bin
+}
+
+// runShInit sources the init output for initShell in the given shell command,
+// runs script with argv as positional parameters and returns the argv the
+// fake tool received along with stderr
+func runShInit(t *testing.T, shellCmd []string, initShell, bin, script string, argv []string) ([]string, string) {
+	t.Helper()
+	return runShInitOpts(t, shellCmd, initShell, initOptions{all: true}, bin, script, argv)
+}
+
+// runShInitOpts is runShInit with explicit --init options
+func runShInitOpts(t *testing.T, shellCmd []string, initShell string, opts initOptions, bin, script string, argv []string) ([]string, string) {
+	t.Helper()
+	path, err := exec.LookPath(shellCmd[0])
+	if err != nil {
+		t.Skipf("%s not installed", shellCmd[0])
+	}
+
+	var init bytes.Buffer
+	if err := printInit(&init, initShell, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	rc := filepath.Join(t.TempDir(), "init.sh")
+	if err := os.WriteFile(rc, init.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := append(slices.Clone(shellCmd[1:]), "-c", `. "$0" && `+script, rc)
+	args = append(args, argv...)
+	cmd := exec.Command(path, args...)
+	cmd.Env = []string{"PATH=" + bin, "REFLAG_TEST_EXEC=1", "HOME=/nonexistent"}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("%s failed: %v\n%s%s", shellCmd[0], err, stdout.String(), stderr.String())
+	}
+
+	var got []string
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected output: %v\n%s", err, stdout.String())
+	}
+	return got, stderr.String()
+}
+
+var posixShells = []struct {
+	name string
+	cmd  []string
+	init string
+}{
+	{"sh", []string{"sh"}, "sh"},
+	{"dash", []string{"dash"}, "sh"},
+	{"bash", []string{"bash"}, "bash"},
+	{"bash-posix", []string{"bash", "--posix"}, "sh"},
+	{"zsh", []string{"zsh"}, "zsh"},
+	{"busybox", []string{"busybox", "sh"}, "sh"},
+	{"mksh", []string{"mksh"}, "ksh"},
+	{"ksh", []string{"ksh"}, "ksh"},
+}
+
+func TestPOSIXInitRoundTrip(t *testing.T) {
+	bin := fakeBin(t, "reflag", "eza")
+
+	// Operands only, so ls2eza keeps them in order and unchanged
+	argv := []string{
+		"plain", "with space", "*", "it's", "$HOME", "", `back\slash`,
+		"tab\there", "!bang", "~", "new\nline", "=ls", "semi;colon", "`tick`",
+	}
+
+	for _, sh := range posixShells {
+		t.Run(sh.name, func(t *testing.T) {
+			got, _ := runShInit(t, sh.cmd, sh.init, bin, `ls "$@"`, argv)
+			if !slices.Equal(got, argv) {
+				t.Errorf("argv did not round-trip under %s:\n got  %q\n want %q", sh.name, got, argv)
+			}
+		})
+	}
+}
+
+func TestShInitFallback(t *testing.T) {
+	argv := []string{"-lt", "with space"}
+
+	for _, sh := range posixShells {
+		t.Run(sh.name+"/missing reflag", func(t *testing.T) {
+			bin := fakeBin(t, "ls")
+			got, stderr := runShInit(t, sh.cmd, sh.init, bin, `ls "$@"`, argv)
+			if !slices.Equal(got, argv) {
+				t.Errorf("fallback argv = %q, want %q", got, argv)
+			}
+			if !strings.Contains(stderr, "reflag: translation failed") {
+				t.Errorf("expected a warning on stderr, got %q", stderr)
+			}
+		})
+
+		t.Run(sh.name+"/refused", func(t *testing.T) {
+			bin := fakeBin(t, "reflag", "ls")
+			got, stderr := runShInit(t, sh.cmd, sh.init, bin, `__reflag_run ls nosuchtool "" "$@"`, argv)
+			if !slices.Equal(got, argv) {
+				t.Errorf("fallback argv = %q, want %q", got, argv)
+			}
+			if strings.Contains(stderr, "reflag: translation failed") {
+				t.Errorf("refusal should fall back quietly, got %q", stderr)
+			}
+		})
+	}
+}
+
+func TestPrintInitWidget(t *testing.T) {
+	tests := []struct {
+		shell    string
+		mode     string
+		contains []string
+	}{
+		{"zsh", "key", []string{"BUFFER=$__reflag_line", "bindkey '^Xt' __reflag_rewrite"}},
+		{"zsh", "enter", []string{"zle -N accept-line __reflag_accept_line"}},
+		{"bash", "key", []string{"READLINE_LINE=$__reflag_line", `bind -x '"\C-xt": __reflag_rewrite'`}},
+		{"bash", "enter", []string{`bind '"\C-m": "\C-xt\C-j"'`}},
+		{"fish", "key", []string{"commandline -r --", `bind \cxt __reflag_rewrite`}},
+		{"fish", "enter", []string{`bind \r __reflag_rewrite execute`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell+"/"+tt.mode, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printInit(&buf, tt.shell, []string{"-grep2rg"}, initOptions{all: true, widget: tt.mode}); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if !strings.Contains(out, "--rewrite --translators=") || strings.Contains(out, "grep2rg") {
+				t.Errorf("widget should pass the selected translators to --rewrite:\n%s", out)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(out, want) {
+					t.Errorf("printInit(%s, widget=%s) missing %q in:\n%s", tt.shell, tt.mode, want, out)
+				}
+			}
+		})
+	}
+
+	if err := printInit(&bytes.Buffer{}, "nu", nil, initOptions{all: true, widget: "key"}); err == nil {
+		t.Error("printInit(nu, widget) should fail")
+	}
+}
+
+func TestBashWidgetRewrite(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	bin := fakeBin(t, "reflag")
+
+	var init bytes.Buffer
+	if err := printInit(&init, "bash", nil, initOptions{all: true, widget: "key"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"translated", `ls -lt "my dir" | head`, `eza -l --sort=modified --reverse "my dir" | head`},
+		// Enter runs the widget too, so a line reflag can't parse is left
+		// alone without an error
+		{"unclosed quote", `ls -lt "my dir`, `ls -lt "my dir`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Anything the widget prints lands in out, ahead of the line
+			script := init.String() + "\nREADLINE_LINE=" + shellQuote(tt.line) + `
+__reflag_rewrite 2>&1
+printf '%s' "$READLINE_LINE"
+`
+			cmd := exec.Command(bash, "-c", script)
+			cmd.Env = []string{"PATH=" + bin, "REFLAG_TEST_EXEC=1"}
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("bash failed: %v", err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("output = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestShInitToggles(t *testing.T) {
+	argv := []string{"-lt", "with space"}
+	scripts := []struct {
+		name   string
+		script string
+	}{
+		{"REFLAG_DISABLE", `REFLAG_DISABLE=1; ls "$@"`},
+		{"per-translator variable", `REFLAG_DISABLE_LS2EZA=1; ls "$@"`},
+		{"reflag off", `reflag off; ls "$@"`},
+		{"reflag off name", `reflag off ls2eza; ls "$@"`},
+		{"reflag pause", `reflag pause 5m; ls "$@"`},
+	}
+
+	for _, sh := range posixShells {
+		for _, sc := range scripts {
+			t.Run(sh.name+"/"+sc.name, func(t *testing.T) {
+				bin := fakeBin(t, "reflag", "ls")
+				if date, err := exec.LookPath("date"); err == nil {
+					os.Symlink(date, filepath.Join(bin, "date"))
+				}
+				got, _ := runShInit(t, sh.cmd, sh.init, bin, sc.script, argv)
+				if !slices.Equal(got, argv) {
+					t.Errorf("disabled wrapper ran %q, want ls with %q", got, argv)
+				}
+			})
+		}
+
+		t.Run(sh.name+"/reflag on", func(t *testing.T) {
+			bin := fakeBin(t, "reflag", "eza")
+			got, _ := runShInit(t, sh.cmd, sh.init, bin, `reflag off; reflag on; ls "$@"`, []string{"-a"})
+			if !slices.Equal(got, []string{"-a"}) {
+				t.Errorf("re-enabled wrapper ran %q, want eza -a", got)
+			}
+		})
+	}
+}
+
+func TestSelectTranslators(t *testing.T) {
+	tests := []struct {
+		selection []string
+		want      []string
+	}{
+		{[]string{"ls2eza", "grep2rg"}, []string{"grep2rg", "ls2eza"}},
+		{[]string{"pagers"}, []string{"less2moor", "more2moor"}},
+		{[]string{"*2moor", "+find2fd"}, []string{"find2fd", "less2moor", "more2moor"}},
+	}
+	for _, tt := range tests {
+		if got := selectTranslators(tt.selection); !slices.Equal(got, tt.want) {
+			t.Errorf("selectTranslators(%q) = %q, want %q", tt.selection, got, tt.want)
+		}
+	}
+
+	defaults := selectTranslators(nil)
+	without := selectTranslators([]string{"-ls2eza"})
+	if len(without) != len(defaults)-1 || slices.Contains(without, "ls2eza") {
+		t.Errorf("-ls2eza: got %q from defaults %q", without, defaults)
+	}
+}
+
+func TestPrintInitSkipsMissingTargets(t *testing.T) {
+	t.Setenv("PATH", fakeBin(t, "eza", "rg"))
+
+	var buf bytes.Buffer
+	if err := printInit(&buf, "bash", []string{"ls2eza", "grep2rg", "less2moor"}, initOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"# Skipped because the target tool is not on PATH (use --all to include):\n#   less2moor: moor not found\n",
+		"ls() {\n",
+		"grep() {\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "less() {") {
+		t.Errorf("less wrapper emitted without moor on PATH:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := printInit(&buf, "bash", []string{"less2moor"}, initOptions{all: true}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "less() {") || strings.Contains(out, "Skipped") {
+		t.Errorf("--all should keep less2moor without a skip note:\n%s", out)
+	}
+}
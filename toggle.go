@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// Environment variables that switch translation off at runtime.
+// REFLAG_DISABLE applies to every translator, REFLAG_DISABLE_<NAME> (e.g.
+// REFLAG_DISABLE_LS2EZA) to a single one. An empty value or "0" leaves
+// translation on, "until:<unix time>" turns it off until that time, and any
+// other value turns it off.
+const disableEnv = "REFLAG_DISABLE"
+
+// disableEnvFor returns the per-translator disable variable for name
+func disableEnvFor(name string) string {
+	return disableEnv + "_" + strings.ToUpper(name)
+}
+
+// disabledValue reports whether a disable variable value switches translation off
+func disabledValue(v string, now time.Time) bool {
+	switch {
+	case v == "" || v == "0":
+		return false
+	case strings.HasPrefix(v, "until:"):
+		deadline, err := strconv.ParseInt(strings.TrimPrefix(v, "until:"), 10, 64)
+		return err == nil && now.Unix() < deadline
+	}
+	return true
+}
+
+// translatorDisabled reports whether translation is switched off for name
+func translatorDisabled(name string) bool {
+	now := time.Now()
+	return disabledValue(os.Getenv(disableEnv), now) || disabledValue(os.Getenv(disableEnvFor(name)), now)
+}
+
+// printPassthrough prints the untranslated source command. In shell format
+// it is prefixed with command so an eval inside a wrapper function runs the
+// real tool; in JSON format the tool is resolved to a path for the same reason.
+func printPassthrough(source string, args []string, format string) {
+	if format == "json" {
+		if path, err := exec.LookPath(source); err == nil {
+			source = path
+		}
+		out, _ := json.Marshal(append([]string{source}, args...))
+		fmt.Println(string(out))
+		return
+	}
+
+	parts := []string{"command", shellQuote(source)}
+	for _, arg := range args {
+		parts = append(parts, shellQuote(arg))
+	}
+	fmt.Println(strings.Join(parts, " "))
+}
+
+// runToggle implements reflag on|off [name] and reflag pause DURATION [name].
+// A program can't change its parent shell's environment, so it prints shell
+// code setting the variables; the reflag function from --init evaluates it.
+func runToggle(command string, args []string) {
+	shell := "sh"
+	var rest []string
+	for _, arg := range args {
+		if after, ok := strings.CutPrefix(arg, "--shell="); ok {
+			shell = after
+		} else {
+			rest = append(rest, arg)
+		}
+	}
+
+	value := "1"
+	if command == "pause" {
+		if len(rest) == 0 {
+			fmt.Fprintln(os.Stderr, "usage: reflag pause DURATION [translator]")
+			os.Exit(exitError)
+		}
+		d, err := time.ParseDuration(rest[0])
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "error: invalid duration %q (e.g. 30m, 1h)\n", rest[0])
+			os.Exit(exitError)
+		}
+		value = fmt.Sprintf("until:%d", time.Now().Add(d).Unix())
+		rest = rest[1:]
+	}
+
+	if len(rest) > 1 {
+		fmt.Fprintf(os.Stderr, "usage: reflag %s [translator]\n", command)
+		os.Exit(exitError)
+	}
+
+	var vars []string
+	switch {
+	case len(rest) == 1:
+		if translator.GetByName(rest[0]) == nil && prefixCommands[rest[0]] == nil {
+			fmt.Fprintf(os.Stderr, "error: unknown translator %q\n", rest[0])
+			os.Exit(exitError)
+		}
+		vars = []string{disableEnvFor(rest[0])}
+	case command == "on":
+		// Switching everything back on also clears per-translator switches
+		names := append(translator.List(), prefixNames()...)
+		sort.Strings(names)
+		vars = []string{disableEnv}
+		for _, name := range names {
+			vars = append(vars, disableEnvFor(name))
+		}
+	default:
+		vars = []string{disableEnv}
+	}
+
+	fish := shell == "fish"
+	if command == "on" {
+		if fish {
+			fmt.Printf("set -e %s\n", strings.Join(vars, " "))
+		} else {
+			fmt.Printf("unset %s\n", strings.Join(vars, " "))
+		}
+		return
+	}
+	for _, v := range vars {
+		if fish {
+			fmt.Printf("set -gx %s %s\n", v, value)
+		} else {
+			fmt.Printf("export %s=%s\n", v, value)
+		}
+	}
+}
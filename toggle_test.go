@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDisabledValue(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"0", false},
+		{"1", true},
+		{"yes", true},
+		{"until:1001", true},
+		{"until:1000", false},
+		{"until:999", false},
+		{"until:garbage", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := disabledValue(tt.value, now); got != tt.expected {
+				t.Errorf("disabledValue(%q) = %v, want %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslatorDisabled(t *testing.T) {
+	if translatorDisabled("ls2eza") {
+		t.Fatal("ls2eza should be enabled without any variables set")
+	}
+
+	t.Setenv("REFLAG_DISABLE_GREP2RG", "1")
+	if !translatorDisabled("grep2rg") {
+		t.Error("REFLAG_DISABLE_GREP2RG should disable grep2rg")
+	}
+	if translatorDisabled("ls2eza") {
+		t.Error("REFLAG_DISABLE_GREP2RG should not disable ls2eza")
+	}
+
+	t.Setenv("REFLAG_DISABLE", "1")
+	if !translatorDisabled("ls2eza") {
+		t.Error("REFLAG_DISABLE should disable every translator")
+	}
+}
@@ -0,0 +1,17 @@
+// Code generated by go generate; DO NOT EDIT.
+
+package all
+
+import (
+	_ "github.com/kluzzebass/reflag/translator/bat2cat"
+	_ "github.com/kluzzebass/reflag/translator/df2duf"
+	_ "github.com/kluzzebass/reflag/translator/dig2doggo"
+	_ "github.com/kluzzebass/reflag/translator/du2dust"
+	_ "github.com/kluzzebass/reflag/translator/find2fd"
+	_ "github.com/kluzzebass/reflag/translator/grep2rg"
+	_ "github.com/kluzzebass/reflag/translator/less2moor"
+	_ "github.com/kluzzebass/reflag/translator/ls2eza"
+	_ "github.com/kluzzebass/reflag/translator/more2moor"
+	_ "github.com/kluzzebass/reflag/translator/ps2procs"
+	_ "github.com/kluzzebass/reflag/translator/screen2tmux"
+)
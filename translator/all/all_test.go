@@ -0,0 +1,53 @@
+package all
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/kluzzebass/reflag/internal/scaffold"
+	"github.com/kluzzebass/reflag/translator"
+)
+
+func TestAllUpToDate(t *testing.T) {
+	want, err := scaffold.AllSource("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("all.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("all.go is out of date; run go generate ./translator/all")
+	}
+}
+
+func TestAllRegistered(t *testing.T) {
+	names, err := scaffold.Translators("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(translator.List()); got != len(names) {
+		t.Errorf("%d translators registered, want one per package (%d)", got, len(names))
+	}
+}
+
+func TestAllDescribed(t *testing.T) {
+	for _, name := range translator.List() {
+		tr := translator.GetByName(name)
+		if _, ok := tr.(translator.Described); !ok {
+			t.Errorf("%s does not implement translator.Described", name)
+			continue
+		}
+		m := translator.Describe(tr)
+		if m.Description == "" || m.Homepage == "" || len(m.Tags) == 0 || len(m.Install) == 0 {
+			t.Errorf("%s has incomplete metadata: %+v", name, m)
+		}
+		for manager := range m.Install {
+			if translator.InstallCommand(manager, "x") == "" {
+				t.Errorf("%s has an install hint for unknown package manager %q", name, manager)
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+// Package all registers every translator. Import it for its side effects:
+//
+//	import _ "github.com/kluzzebass/reflag/translator/all"
+//
+// The import list in all.go is generated; run go generate after adding a
+// translator, or scaffold it with go run -tags dev . dev new-translator,
+// which does it for you.
+package all
+
+//go:generate go run gen.go
@@ -0,0 +1,18 @@
+//go:build ignore
+
+// gen regenerates all.go from the translator directories
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kluzzebass/reflag/internal/scaffold"
+)
+
+func main() {
+	if err := scaffold.WriteAll("../.."); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -18,11 +18,68 @@ func (t *Translator) SourceTool() string  { return "cat" }
 func (t *Translator) TargetTool() string  { return "bat" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Shows files with bat, a cat with syntax highlighting",
+		Homepage:    "https://github.com/sharkdp/bat",
+		Tags:        []string{"files"},
+		MinVersion:  "0.18.0",
+		Install: map[string]string{
+			"apt":    "bat",
+			"dnf":    "bat",
+			"pacman": "bat",
+			"brew":   "bat",
+			"cargo":  "bat",
+		},
+		Binaries: map[string]string{"apt": "batcat"},
+	}
+}
+
 // Translate converts cat arguments to bat arguments to make bat behave like cat
 func (t *Translator) Translate(args []string, mode string) []string {
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	_, _, unknown := knownOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, fidelity), "cat", unknown)
+}
+
+// knownOptions are the options translateFlags recognises: cat's, and bat's
+// own, which it drops. Others pass through to bat untranslated.
+var knownOptions = translator.Getopt{
+	Short: "nsuAvpdfLrSVhl:H:m:",
+	Long: []string{
+		"number", "squeeze-blank", "show-all", "unbuffered",
+		"plain", "force-colorization", "diff", "list-themes", "list-languages",
+		"chop-long-lines", "diagnostic", "acknowledgements", "set-terminal-title",
+		"help", "version", "completion:",
+		"language:", "highlight-line:", "file-name:", "diff-context:", "tabs:",
+		"wrap:", "terminal-width:", "color:", "italic-text:", "decorations:",
+		"paging:", "pager:", "map-syntax:", "ignored-suffix:", "theme:",
+		"theme-light:", "theme-dark:", "style:", "line-range:", "squeeze-limit:",
+		"strip-ansi:", "nonprintable-notation:", "binary:",
+	},
+	Exact: true,
+}
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-v":                {Fidelity: translator.Approximate, Note: "bat also marks spaces, tabs and line ends"},
+	"-A":                {Fidelity: translator.Approximate, Note: "bat marks non-printable characters its own way"},
+	"--show-all":        {Fidelity: translator.Approximate, Note: "bat marks non-printable characters its own way"},
+	"-b":                {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"--number-nonblank": {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"-e":                {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"-E":                {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"--show-ends":       {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"-t":                {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"-T":                {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+	"--show-tabs":       {Fidelity: translator.Lossy, Note: "passed through; bat has no such flag"},
+}
+
 // Map of bat short flags to cat equivalents
 var flagMap = map[rune]string{
 	'n': "-n", // --number → -n (line numbers)
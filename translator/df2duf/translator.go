@@ -18,11 +18,87 @@ func (t *Translator) SourceTool() string  { return "df" }
 func (t *Translator) TargetTool() string  { return "duf" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Shows disk usage with duf, a friendlier df",
+		Homepage:    "https://github.com/muesli/duf",
+		Tags:        []string{"system", "disk"},
+		MinVersion:  "0.6.0",
+		Install: map[string]string{
+			"apt":    "duf",
+			"dnf":    "duf",
+			"pacman": "duf",
+			"brew":   "duf",
+		},
+	}
+}
+
+// Flavors lists the df dialects the translator knows; see
+// translator.Flavored. GNU and BSD options are told apart without a mode.
+func (t *Translator) Flavors() []string { return []string{"busybox"} }
+
 // Translate converts du arguments to duf arguments
 func (t *Translator) Translate(args []string, mode string) []string {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, _, _ := busyboxOptions.Parse(args)
+		args = translator.OptionArgs(opts)
+	}
 	return translateFlags(args)
 }
 
+// TranslatePiped translates for output read by another program; see
+// translator.Piped. duf's tables are for people, so it is asked for JSON.
+func (t *Translator) TranslatePiped(args []string, mode string) ([]string, bool) {
+	return append([]string{"-json"}, t.Translate(args, mode)...), true
+}
+
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, _, unknown := busyboxOptions.Parse(args)
+		return append(translator.MapFlags(translator.OptionArgs(opts), fidelity), translator.Unsupported("busybox", "df", unknown)...)
+	}
+	_, _, unknown := knownOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, fidelity), "df", unknown)
+}
+
+// busyboxOptions are the options busybox df accepts
+var busyboxOptions = translator.Getopt{Short: "kPTaiB:mht:"}
+
+// knownOptions are the options translateFlags recognises; others pass
+// through to duf untranslated
+var knownOptions = func() translator.Getopt {
+	g := translator.Getopt{
+		Short: "alxhcPLHsAgkmnrS0DTI:B:t:d:",
+		Long:  []string{"all", "one-file-system", "inodes", "exclude::"},
+		Exact: true,
+	}
+	for flag := range ignoredFlags {
+		if strings.HasPrefix(flag, "--") {
+			g.Long = append(g.Long, flag[2:]+"::")
+		}
+	}
+	return g
+}()
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-B":            {Fidelity: translator.Approximate, Note: "sizes are always shown human-readable"},
+	"--block-size":  {Fidelity: translator.Approximate, Note: "sizes are always shown human-readable"},
+	"-k":            {Fidelity: translator.Approximate, Note: "sizes are always shown human-readable"},
+	"-m":            {Fidelity: translator.Approximate, Note: "sizes are always shown human-readable"},
+	"-g":            {Fidelity: translator.Approximate, Note: "sizes are always shown human-readable"},
+	"-P":            {Fidelity: translator.Approximate, Note: "output is not in POSIX format"},
+	"--portability": {Fidelity: translator.Approximate, Note: "output is not in POSIX format"},
+	"-l":            {Fidelity: translator.Lossy, Note: "remote filesystems are listed too"},
+	"--local":       {Fidelity: translator.Lossy, Note: "remote filesystems are listed too"},
+	"-t":            {Fidelity: translator.Lossy, Note: "filesystems are not filtered by type"},
+	"--type":        {Fidelity: translator.Lossy, Note: "filesystems are not filtered by type"},
+	"-x":            {Fidelity: translator.Lossy, Note: "filesystem types are not excluded"},
+	"-i":            {Fidelity: translator.Lossy, Note: "passed through; duf only has -inodes"},
+}
+
 // Flags to ignore or that have no duf equivalent
 var ignoredFlags = map[string]bool{
 	"-A":                 true, // apparent size - no equivalent
@@ -59,6 +135,8 @@ var ignoredFlags = map[string]bool{
 	"-h":                 true, // human-readable is duf default
 	"--human-readable":   true,
 	"--si":               true, // duf uses SI by default
+	"-T":                 true, // print type - duf always shows it
+	"--print-type":       true,
 }
 
 func translateFlags(args []string) []string {
@@ -160,7 +238,7 @@ func translateFlags(args []string) []string {
 						skipNext = true
 					}
 					goto nextArg
-				case 'h', 'c', 'P', 'L', 'H', 's', 'A', 'g', 'k', 'm', 'n', 'r', 'S', '0', 'D':
+				case 'h', 'c', 'P', 'L', 'H', 's', 'A', 'g', 'k', 'm', 'n', 'r', 'S', '0', 'D', 'T':
 					// Ignored flags that are either duf defaults or not applicable
 					continue
 				default:
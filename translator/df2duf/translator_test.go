@@ -3,6 +3,8 @@ package df2duf
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
 )
 
 func TestTranslateFlags(t *testing.T) {
@@ -224,3 +226,53 @@ func TestTranslator(t *testing.T) {
 		t.Errorf("Translate(['-lh', '/tmp'], '') = %v, want %v", result, expected)
 	}
 }
+
+// busyboxUsage is busybox 1.36's df --help
+const busyboxUsage = `Usage: df [-PkmhT] [-t TYPE] [FILESYSTEM]...
+
+Print filesystem usage statistics
+
+	-P	POSIX output format
+	-k	1024-byte blocks (default)
+	-m	1M-byte blocks
+	-h	Human readable (e.g. 1K 243M 2G)
+	-T	Print filesystem type
+	-t TYPE	Print only mounts of this type
+	-a	Show all filesystems
+	-i	Inodes
+	-B SIZE	Blocksize
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := o.Args("ext4")
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox df %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD options busybox df rejects
+	for _, arg := range []string{"-l", "-x", "-g", "--total", "--output=source"} {
+		if got := usage.Rejected(tr.Mappings([]string{arg}, "busybox")); len(got) != 1 {
+			t.Errorf("busybox df %s: rejected = %q, want it", arg, got)
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{"-hT"}, []string{}},
+		{[]string{"-a", "-t", "ext4", "/"}, []string{"-all"}},
+		{[]string{"-l", "--all"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -17,10 +17,97 @@ func (t *Translator) SourceTool() string  { return "dig" }
 func (t *Translator) TargetTool() string  { return "doggo" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Looks up DNS records with doggo, a dig with readable output",
+		Homepage:    "https://github.com/mr-karan/doggo",
+		Tags:        []string{"network"},
+		MinVersion:  "1.0.0",
+		Install: map[string]string{
+			"brew": "doggo",
+		},
+	}
+}
+
 func (t *Translator) Translate(args []string, mode string) []string {
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	_, operands, unknown := knownOptions.Parse(args)
+	for _, arg := range operands {
+		if !strings.HasPrefix(arg, "+") {
+			continue
+		}
+		name, _, _ := strings.Cut(arg[1:], "=")
+		if !plusOptions[strings.TrimPrefix(name, "no")] {
+			unknown = append(unknown, "+"+name)
+		}
+	}
+	return translator.Unknown(translator.MapFlags(args, fidelity), "dig", unknown)
+}
+
+// knownOptions are the flags translateFlags recognises; others pass through
+// to doggo untranslated. +options, @server and the query are operands here.
+var knownOptions = translator.Getopt{Short: "46b:c:f:k:p:q:t:x:muihv", Exact: true}
+
+// plusOptions are the +options handlePlusOption recognises, without "no";
+// it drops the others
+var plusOptions = map[string]bool{
+	"timeout": true, "time": true, "ndots": true, "bufsize": true, "edns": true, "subnet": true,
+	"short": true, "tcp": true, "vc": true, "trace": true, "recurse": true, "dnssec": true,
+	"aa": true, "aaonly": true, "aaflag": true, "ad": true, "adflag": true, "cd": true, "cdflag": true,
+	"nsid": true, "cookie": true, "padding": true, "ede": true, "search": true,
+	"stats": true, "cmd": true, "question": true, "answer": true, "authority": true,
+	"additional": true, "comments": true, "rrcomments": true, "ttlid": true, "cl": true,
+	"qr": true, "split": true, "identify": true, "multiline": true, "onesoa": true,
+	"nssearch": true, "fail": true, "besteffort": true, "keepopen": true, "ignore": true,
+	"crypto": true, "defname": true, "expire": true, "idnout": true,
+	"ednsnegotiation": true, "ednsflags": true, "ednsopt": true,
+}
+
+// Flags and +options that don't translate exactly; +noX is graded as +X
+var fidelity = map[string]translator.Mapping{
+	"+all":             {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+stats":           {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+cmd":             {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+question":        {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+answer":          {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+authority":       {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+additional":      {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+comments":        {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+rrcomments":      {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+ttlid":           {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+cl":              {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+qr":              {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+split":           {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+identify":        {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+multiline":       {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"+onesoa":          {Fidelity: translator.Approximate, Note: "doggo formats its output its own way"},
+	"-u":               {Fidelity: translator.Approximate, Note: "times are not shown in microseconds"},
+	"+trace":           {Fidelity: translator.Lossy, Note: "the delegation path is not traced"},
+	"+nssearch":        {Fidelity: translator.Lossy, Note: "authoritative servers are not queried"},
+	"+bufsize":         {Fidelity: translator.Lossy, Note: "EDNS settings are not applied"},
+	"+edns":            {Fidelity: translator.Lossy, Note: "EDNS settings are not applied"},
+	"+ednsnegotiation": {Fidelity: translator.Lossy, Note: "EDNS settings are not applied"},
+	"+ednsflags":       {Fidelity: translator.Lossy, Note: "EDNS settings are not applied"},
+	"+ednsopt":         {Fidelity: translator.Lossy, Note: "EDNS settings are not applied"},
+	"+fail":            {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+besteffort":      {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+keepopen":        {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+ignore":          {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+crypto":          {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+defname":         {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+expire":          {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"+idnout":          {Fidelity: translator.Lossy, Note: "doggo has no equivalent"},
+	"-b":               {Fidelity: translator.Lossy, Note: "the source address is not set"},
+	"-f":               {Fidelity: translator.Lossy, Note: "batch files are not read"},
+	"-k":               {Fidelity: translator.Lossy, Note: "TSIG keys are not used"},
+	"-p":               {Fidelity: translator.Lossy, Note: "the port is not set"},
+}
+
 func translateFlags(args []string) []string {
 	var result []string
 	var queryName string
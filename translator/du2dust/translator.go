@@ -18,11 +18,91 @@ func (t *Translator) SourceTool() string  { return "du" }
 func (t *Translator) TargetTool() string  { return "dust" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Sums directory sizes with dust, a du that draws a tree",
+		Homepage:    "https://github.com/bootandy/dust",
+		Tags:        []string{"system", "disk"},
+		MinVersion:  "0.8.0",
+		Install: map[string]string{
+			"apt":    "dust",
+			"dnf":    "dust",
+			"pacman": "dust",
+			"brew":   "dust",
+			"cargo":  "du-dust",
+		},
+	}
+}
+
+// Flavors lists the du dialects the translator knows; see
+// translator.Flavored. GNU and BSD options are told apart without a mode.
+func (t *Translator) Flavors() []string { return []string{"busybox"} }
+
 // Translate converts du arguments to dust arguments
 func (t *Translator) Translate(args []string, mode string) []string {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, operands, _ := busyboxOptions.Parse(args)
+		return append(translateFlags(translator.OptionArgs(opts)), translator.Operands(operands)...)
+	}
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, _, unknown := busyboxOptions.Parse(args)
+		return append(translator.MapFlags(translator.OptionArgs(opts), fidelity), translator.Unsupported("busybox", "du", unknown)...)
+	}
+	_, _, unknown := knownOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, fidelity), "du", unknown)
+}
+
+// knownOptions are the options translateFlags recognises; others pass
+// through to dust untranslated
+var knownOptions = func() translator.Getopt {
+	g := translator.Getopt{
+		Short: "saLxbkmghcPlSHD0d:t:I:B:X:",
+		Long: []string{
+			"max-depth::", "exclude::", "threshold::", "block-size::",
+			"summarize", "all", "dereference", "one-file-system",
+			"apparent-size", "si", "bytes", "inodes",
+		},
+		Exact: true,
+	}
+	for flag := range ignoredFlags {
+		if strings.HasPrefix(flag, "--") {
+			g.Long = append(g.Long, flag[2:])
+		}
+	}
+	return g
+}()
+
+// busyboxOptions are the options busybox du accepts. They mean what they
+// do in GNU du.
+var busyboxOptions = translator.Getopt{Short: "aHkLsxd:lchm"}
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-t":                 {Fidelity: translator.Approximate, Note: "dust hides small entries by its own rules"},
+	"--threshold":        {Fidelity: translator.Approximate, Note: "dust hides small entries by its own rules"},
+	"-I":                 {Fidelity: translator.Approximate, Note: "the pattern is read as a regular expression"},
+	"-B":                 {Fidelity: translator.Approximate, Note: "sizes are shown in dust's units"},
+	"--block-size":       {Fidelity: translator.Approximate, Note: "sizes are shown in dust's units"},
+	"-H":                 {Fidelity: translator.Approximate, Note: "symlinks given as arguments are not followed"},
+	"-D":                 {Fidelity: translator.Approximate, Note: "symlinks given as arguments are not followed"},
+	"--dereference-args": {Fidelity: translator.Approximate, Note: "symlinks given as arguments are not followed"},
+	"-l":                 {Fidelity: translator.Lossy, Note: "hard links are counted once"},
+	"--count-links":      {Fidelity: translator.Lossy, Note: "hard links are counted once"},
+	"-S":                 {Fidelity: translator.Lossy, Note: "subdirectory sizes are included"},
+	"--separate-dirs":    {Fidelity: translator.Lossy, Note: "subdirectory sizes are included"},
+	"--time":             {Fidelity: translator.Lossy, Note: "times are not shown"},
+	"--time-style":       {Fidelity: translator.Lossy, Note: "times are not shown"},
+	"-0":                 {Fidelity: translator.Lossy, Note: "lines are not NUL-terminated"},
+	"--null":             {Fidelity: translator.Lossy, Note: "lines are not NUL-terminated"},
+	"-X":                 {Fidelity: translator.Lossy, Note: "the exclude file is not read"},
+}
+
 // Flags to ignore (dust handles automatically or no equivalent)
 var ignoredFlags = map[string]bool{
 	"-h":                 true, // dust is human-readable by default
@@ -3,6 +3,8 @@ package du2dust
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
 )
 
 func TestTranslateFlags(t *testing.T) {
@@ -211,3 +213,56 @@ func TestTranslatorInterface(t *testing.T) {
 		t.Errorf("TargetTool() = %q, want %q", tr.TargetTool(), "dust")
 	}
 }
+
+// busyboxUsage is busybox 1.36's du --help
+const busyboxUsage = `Usage: du [-aHLdclsxhmk] [FILE]...
+
+Summarize disk space used for FILEs (or directories)
+
+	-a	Show file sizes too
+	-L	Follow all symlinks
+	-H	Follow symlinks on command line
+	-d N	Limit output to directories (and files with -a) of depth < N
+	-c	Show grand total
+	-l	Count sizes many times if hard linked
+	-s	Display only a total for each argument
+	-x	Skip directories on different filesystems
+	-h	Sizes in human readable format (e.g., 1K 243M 2G)
+	-m	Sizes in megabytes
+	-k	Sizes in kilobytes (default)
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := o.Args("1")
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox du %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD options busybox du rejects
+	for _, arg := range []string{"-b", "-g", "-t", "-I", "-B", "-X", "--max-depth=1", "--apparent-size"} {
+		if got := usage.Rejected(tr.Mappings([]string{arg}, "busybox")); len(got) != 1 {
+			t.Errorf("busybox du %s: rejected = %q, want it", arg, got)
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{"-sh", "dir"}, []string{"-d", "0", "dir"}},
+		{[]string{"-d", "2", "-a", "."}, []string{"-d", "2", "-F", "."}},
+		{[]string{"dir", "-m", "--apparent-size"}, []string{"-o", "mb", "dir"}},
+		{[]string{"-s", "--", "-dir"}, []string{"-d", "0", "--", "-dir"}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
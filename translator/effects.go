@@ -0,0 +1,19 @@
+package translator
+
+// Mutating is implemented by translators whose commands can change
+// something besides printing output, such as deleting files or starting
+// sessions, so they must not be run just to look at what they print
+type Mutating interface {
+	// SideEffect describes what running the source command with args
+	// changes (e.g. "-delete deletes files"), or returns "" if nothing
+	SideEffect(args []string, mode string) string
+}
+
+// SideEffect describes what running the source command of t with args
+// changes, or returns "" if nothing or t doesn't implement Mutating
+func SideEffect(t Translator, args []string, mode string) string {
+	if m, ok := t.(Mutating); ok {
+		return m.SideEffect(args, mode)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Fidelity grades how faithfully a translation keeps the meaning of the
+// source command. Grades are ordered, so a translation meets a minimum
+// when its grade is >= the minimum.
+type Fidelity int
+
+const (
+	// Lossy translations drop or change the meaning of a flag (find -perm)
+	Lossy Fidelity = iota
+	// Approximate translations behave similarly but not identically
+	// (find -atime filters on modification time in fd)
+	Approximate
+	// Exact translations behave the same (grep -i)
+	Exact
+)
+
+var fidelityNames = []string{"lossy", "approximate", "exact"}
+
+func (f Fidelity) String() string {
+	if f < 0 || int(f) >= len(fidelityNames) {
+		return fmt.Sprintf("Fidelity(%d)", int(f))
+	}
+	return fidelityNames[f]
+}
+
+// ParseFidelity parses a fidelity name: exact, approximate (or approx) or lossy
+func ParseFidelity(s string) (Fidelity, error) {
+	switch strings.ToLower(s) {
+	case "exact":
+		return Exact, nil
+	case "approximate", "approx":
+		return Approximate, nil
+	case "lossy":
+		return Lossy, nil
+	}
+	return Lossy, fmt.Errorf("unknown fidelity %q (expected exact, approximate or lossy)", s)
+}
+
+// Mapping is the fidelity of the translation of one source flag
+type Mapping struct {
+	Flag     string // source flag as given, e.g. "-atime" or "-L"
+	Fidelity Fidelity
+	Note     string // what is lost or changed
+}
+
+// Graded is implemented by translators that grade their translation of
+// each source flag. Only flags that don't translate exactly need to be
+// reported, including those the translator doesn't recognise; see Unknown.
+type Graded interface {
+	Mappings(args []string, mode string) []Mapping
+}
+
+// Grade returns the overall fidelity of translating args with t, which is
+// the lowest fidelity among its mappings, along with the mappings.
+// Translations without mappings, including those by translators that
+// don't implement Graded, are Exact.
+func Grade(t Translator, args []string, mode string) (Fidelity, []Mapping) {
+	g, ok := t.(Graded)
+	if !ok {
+		return Exact, nil
+	}
+	mappings := g.Mappings(args, mode)
+	grade := Exact
+	for _, m := range mappings {
+		grade = min(grade, m.Fidelity)
+	}
+	return grade, mappings
+}
+
+// MapFlags grades the flags in args of a tool with getopt-style flags using
+// table, which lists the flags that don't translate exactly (Flag may be left
+// empty there). Long flags are looked up without any =value, bundled short
+// flags one by one (-la as -l and -a) up to an attached number (-A3), and
+// dig-style +[no]options as +option. Scanning stops at --. Values of flags
+// are not known here, so a value that looks like a flag is graded as one.
+func MapFlags(args []string, table map[string]Mapping) []Mapping {
+	var mappings []Mapping
+	add := func(arg, flag string) {
+		if m, ok := table[flag]; ok {
+			m.Flag = arg
+			mappings = append(mappings, m)
+		}
+	}
+	for _, arg := range args {
+		switch {
+		case arg == "--":
+			return mappings
+		case strings.HasPrefix(arg, "--"):
+			name, _, _ := strings.Cut(arg, "=")
+			add(name, name)
+		case strings.HasPrefix(arg, "+") && len(arg) > 1:
+			name, _, _ := strings.Cut(arg[1:], "=")
+			add("+"+name, "+"+strings.TrimPrefix(name, "no"))
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for k := 1; k < len(arg); k++ {
+				if k > 1 && arg[k] >= '0' && arg[k] <= '9' {
+					break
+				}
+				add("-"+arg[k:k+1], "-"+arg[k:k+1])
+			}
+		}
+	}
+	return mappings
+}
+
+// MapWords grades args of a tool whose options are whole words (find
+// -name), looking each argument up in table as MapFlags does
+func MapWords(args []string, table map[string]Mapping) []Mapping {
+	var mappings []Mapping
+	for _, arg := range args {
+		if m, ok := table[arg]; ok {
+			m.Flag = arg
+			mappings = append(mappings, m)
+		}
+	}
+	return mappings
+}
@@ -0,0 +1,118 @@
+package translator
+
+import (
+	"reflect"
+	"testing"
+)
+
+// gradedTranslator is a mockTranslator that grades with MapFlags
+type gradedTranslator struct {
+	mockTranslator
+	table map[string]Mapping
+}
+
+func (g *gradedTranslator) Mappings(args []string, mode string) []Mapping {
+	return MapFlags(args, g.table)
+}
+
+func TestParseFidelity(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Fidelity
+		wantErr bool
+	}{
+		{"exact", Exact, false},
+		{"Approximate", Approximate, false},
+		{"approx", Approximate, false},
+		{"lossy", Lossy, false},
+		{"perfect", Lossy, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFidelity(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFidelity(%q) = %v, %v", tt.input, got, err)
+		}
+		if err == nil && got.String() == "" {
+			t.Errorf("%v has no name", got)
+		}
+	}
+	if !(Lossy < Approximate && Approximate < Exact) {
+		t.Error("fidelity grades are out of order")
+	}
+}
+
+func TestMapFlags(t *testing.T) {
+	table := map[string]Mapping{
+		"-b":     {Fidelity: Lossy},
+		"-G":     {Fidelity: Approximate},
+		"--hide": {Fidelity: Lossy},
+		"+trace": {Fidelity: Lossy},
+		"-3":     {Fidelity: Lossy},
+	}
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"none", []string{"-i", "pattern", "file"}, nil},
+		{"short", []string{"-b"}, []string{"-b"}},
+		{"bundle", []string{"-iGb"}, []string{"-G", "-b"}},
+		{"attached number", []string{"-A3"}, nil},
+		{"leading digit", []string{"-3"}, []string{"-3"}},
+		{"long with value", []string{"--hide=*.o"}, []string{"--hide"}},
+		{"plus option", []string{"+notrace"}, []string{"+notrace"}},
+		{"after double dash", []string{"--", "-b"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range MapFlags(tt.args, table) {
+				got = append(got, m.Flag)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MapFlags(%q) flags = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrade(t *testing.T) {
+	g := &gradedTranslator{
+		mockTranslator: mockTranslator{name: "x2y", source: "x", target: "y"},
+		table: map[string]Mapping{
+			"-a": {Fidelity: Approximate, Note: "close"},
+			"-l": {Fidelity: Lossy, Note: "gone"},
+		},
+	}
+	tests := []struct {
+		args []string
+		want Fidelity
+	}{
+		{nil, Exact},
+		{[]string{"-x", "file"}, Exact},
+		{[]string{"-a"}, Approximate},
+		{[]string{"-a", "-l"}, Lossy},
+	}
+
+	for _, tt := range tests {
+		if got, _ := Grade(g, tt.args, ""); got != tt.want {
+			t.Errorf("Grade(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+
+	plain := &mockTranslator{name: "p2q", source: "p", target: "q"}
+	if got, mappings := Grade(plain, []string{"-l"}, ""); got != Exact || mappings != nil {
+		t.Errorf("ungraded translator = %v, %v; want exact", got, mappings)
+	}
+
+	unknown := Unknown(MapFlags([]string{"-l", "--bogus=1", "-Q"}, g.table), "x", []string{"-l", "--bogus=1", "-Q"})
+	if len(unknown) != 3 || unknown[0].Note != "gone" || unknown[1].Flag != "--bogus" || unknown[2].Fidelity != Lossy {
+		t.Errorf("Unknown = %+v, want -l graded once and --bogus and -Q as lossy", unknown)
+	}
+
+	if got := MapWords([]string{"-l", "-al"}, g.table); len(got) != 1 || got[0].Flag != "-l" || got[0].Note != "gone" {
+		t.Errorf("MapWords = %+v", got)
+	}
+}
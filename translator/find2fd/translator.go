@@ -19,11 +19,192 @@ func (t *Translator) SourceTool() string  { return "find" }
 func (t *Translator) TargetTool() string  { return "fd" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Finds files with fd, a simpler and faster find",
+		Homepage:    "https://github.com/sharkdp/fd",
+		Tags:        []string{"search", "files"},
+		MinVersion:  "8.0.0",
+		Install: map[string]string{
+			"apt":    "fd-find",
+			"dnf":    "fd-find",
+			"pacman": "fd",
+			"brew":   "fd",
+			"cargo":  "fd-find",
+		},
+		Binaries: map[string]string{"apt": "fdfind"},
+	}
+}
+
+// Flavors lists the find dialects the translator knows; see
+// translator.Flavored. GNU and BSD expressions are told apart without a mode.
+func (t *Translator) Flavors() []string { return []string{"busybox"} }
+
 // Translate converts find arguments to fd arguments
 func (t *Translator) Translate(args []string, mode string) []string {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		args, _ = parseBusybox(args)
+	}
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	var mappings []translator.Mapping
+	if translator.ResolveMode(t, mode) == "busybox" {
+		var unknown []string
+		args, unknown = parseBusybox(args)
+		mappings = translator.Unsupported("busybox", "find", unknown)
+		mappings = append(mappings, translator.MapWords(args, busyboxFidelity)...)
+	}
+	mappings = append(mappings, translator.MapWords(args, fidelity)...)
+	mappings = translator.Unknown(mappings, "find", unknownExpressions(args))
+	for i := 0; i+1 < len(args); i++ {
+		switch val := args[i+1]; args[i] {
+		case "-type":
+			if val == "b" || val == "c" {
+				mappings = append(mappings, translator.Mapping{Flag: "-type " + val, Fidelity: translator.Approximate, Note: "device files are matched as regular files"})
+			}
+		case "-mtime", "-mmin":
+			if !strings.HasPrefix(val, "+") && !strings.HasPrefix(val, "-") {
+				mappings = append(mappings, translator.Mapping{Flag: args[i] + " " + val, Fidelity: translator.Approximate, Note: "an exact age is read as a maximum age"})
+			}
+		}
+	}
+	return mappings
+}
+
+// SideEffect reports the actions in args that change something; see
+// translator.Mutating
+func (t *Translator) SideEffect(args []string, mode string) string {
+	for _, arg := range args {
+		if effect, ok := sideEffects[arg]; ok {
+			return arg + " " + effect
+		}
+	}
+	return ""
+}
+
+// Actions that do more than print
+var sideEffects = map[string]string{
+	"-delete":  "deletes files",
+	"-exec":    "runs commands",
+	"-execdir": "runs commands",
+	"-ok":      "runs commands",
+	"-okdir":   "runs commands",
+	"-fls":     "writes to a file",
+	"-fprint":  "writes to a file",
+	"-fprint0": "writes to a file",
+	"-fprintf": "writes to a file",
+}
+
+// Expressions that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-atime":    {Fidelity: translator.Approximate, Note: "fd filters on modification time, not access time"},
+	"-amin":     {Fidelity: translator.Approximate, Note: "fd filters on modification time, not access time"},
+	"-ctime":    {Fidelity: translator.Approximate, Note: "fd filters on modification time, not status change time"},
+	"-cmin":     {Fidelity: translator.Approximate, Note: "fd filters on modification time, not status change time"},
+	"-size":     {Fidelity: translator.Approximate, Note: "sizes are rounded differently"},
+	"-regex":    {Fidelity: translator.Approximate, Note: "the regex matches the file name, not the whole path"},
+	"-iregex":   {Fidelity: translator.Approximate, Note: "the regex matches the file name, not the whole path"},
+	"-depth":    {Fidelity: translator.Approximate, Note: "results are not listed depth first"},
+	"-daystart": {Fidelity: translator.Approximate, Note: "times are not measured from the start of the day"},
+	"-perm":     {Fidelity: translator.Lossy, Note: "fd has no permission filter"},
+	"-H":        {Fidelity: translator.Lossy, Note: "fd -H shows hidden files instead of following symlinks on the command line"},
+	"-prune":    {Fidelity: translator.Lossy, Note: "pruned directories are searched"},
+	"-delete":   {Fidelity: translator.Lossy, Note: "files are not deleted"},
+	"-exec":     {Fidelity: translator.Lossy, Note: "the command is not run"},
+	"-execdir":  {Fidelity: translator.Lossy, Note: "the command is not run"},
+	"-ok":       {Fidelity: translator.Lossy, Note: "the command is not run"},
+	"-okdir":    {Fidelity: translator.Lossy, Note: "the command is not run"},
+	"!":         {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+	"-not":      {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+	"-o":        {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+	"-or":       {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+	"(":         {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+	")":         {Fidelity: translator.Lossy, Note: "fd has no boolean expressions"},
+}
+
+// busyboxPrimaries are the expression words busybox find accepts, each
+// with whether it takes a value. -exec takes words up to ";" or "+".
+var busyboxPrimaries = map[string]bool{
+	"-follow": false, "-L": false, "-H": false, "-xdev": false, "-depth": false,
+	"-maxdepth": true, "-mindepth": true,
+	"(": false, ")": false, "!": false, "-not": false,
+	"-a": false, "-and": false, "-o": false, "-or": false,
+	"-name": true, "-iname": true, "-path": true, "-ipath": true, "-regex": true,
+	"-type": true, "-executable": false, "-perm": true, "-mtime": true, "-mmin": true,
+	"-newer": true, "-inum": true, "-samefile": true, "-user": true, "-group": true,
+	"-size": true, "-links": true, "-prune": false, "-empty": false,
+	"-print": false, "-print0": false, "-exec": false, "-delete": false, "-quit": false,
+}
+
+// Busybox primaries GNU find mode doesn't grade, as fd has no filter for them
+var busyboxFidelity = map[string]translator.Mapping{
+	"-inum":     {Fidelity: translator.Lossy, Note: "fd has no inode filter"},
+	"-samefile": {Fidelity: translator.Lossy, Note: "fd has no inode filter"},
+	"-links":    {Fidelity: translator.Lossy, Note: "fd has no link count filter"},
+}
+
+// parseBusybox reads args as busybox find does: -H or -L, paths up to the
+// first word starting with "-", "!" or "(", then the expression. Words in the
+// expression busybox find doesn't know are returned in unknown and left out
+// of kept, with their value if one follows; busybox would reject the command.
+func parseBusybox(args []string) (kept, unknown []string) {
+	i := 0
+	for i < len(args) && (args[i] == "-H" || args[i] == "-L") {
+		i++
+	}
+	leading := args[:i]
+	for i < len(args) && !strings.HasPrefix(args[i], "-") && args[i] != "!" && args[i] != "(" {
+		kept = append(kept, args[i])
+		i++
+	}
+	// translateFlags reads paths first, and -H and -L anywhere
+	kept = append(kept, leading...)
+	for ; i < len(args); i++ {
+		arg := args[i]
+		takesValue, ok := busyboxPrimaries[arg]
+		switch {
+		case !ok:
+			// GNU's -execdir, -ok and -okdir take a command like -exec;
+			// a word after other primaries is taken as their value
+			unknown = append(unknown, arg)
+			if arg == "-execdir" || arg == "-ok" || arg == "-okdir" {
+				for i+1 < len(args) && args[i] != ";" && args[i] != "+" {
+					i++
+				}
+			} else if i+1 < len(args) {
+				if _, next := busyboxPrimaries[args[i+1]]; !next && !strings.HasPrefix(args[i+1], "-") {
+					i++
+				}
+			}
+		case arg == "-exec":
+			j := i + 1
+			for j < len(args) && args[j] != ";" && args[j] != "+" {
+				j++
+			}
+			if j == len(args) {
+				unknown = append(unknown, arg)
+				return kept, unknown
+			}
+			kept = append(kept, args[i:j+1]...)
+			i = j
+		case takesValue:
+			if i+1 == len(args) {
+				unknown = append(unknown, arg)
+				return kept, unknown
+			}
+			kept = append(kept, arg, args[i+1])
+			i++
+		default:
+			kept = append(kept, arg)
+		}
+	}
+	return kept, unknown
+}
+
 // Expressions that take a value
 var expressionsWithValue = map[string]bool{
 	"-name":     true,
@@ -57,6 +238,42 @@ var ignoredExpressions = map[string]bool{
 	"-true":   true,
 }
 
+// Expressions without a value that translateFlags recognises, besides
+// ignoredExpressions; -exec and the like take words up to ";" or "+"
+var knownExpressions = map[string]bool{
+	"!": true, "-not": true, "(": true, ")": true, "-o": true, "-or": true,
+	"-L": true, "-follow": true, "-H": true, "-P": true,
+	"-empty": true, "-executable": true, "-xdev": true, "-mount": true,
+	"-depth": true, "-daystart": true, "-delete": true, "-prune": true, "-quit": true,
+	"-exec": true, "-execdir": true, "-ok": true, "-okdir": true,
+}
+
+// unknownExpressions returns the words starting with "-" in the expression
+// that translateFlags doesn't recognise and so drops
+func unknownExpressions(args []string) []string {
+	var unknown []string
+	i := 0
+	for i < len(args) && !strings.HasPrefix(args[i], "-") && args[i] != "!" && args[i] != "(" && args[i] != ")" {
+		i++
+	}
+	for ; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case expressionsWithValue[arg]:
+			i++
+		case arg == "-exec" || arg == "-execdir" || arg == "-ok" || arg == "-okdir":
+			for i+1 < len(args) && args[i+1] != ";" && args[i+1] != "+" {
+				i++
+			}
+			i++
+		case knownExpressions[arg], ignoredExpressions[arg]:
+		case strings.HasPrefix(arg, "-"):
+			unknown = append(unknown, arg)
+		}
+	}
+	return unknown
+}
+
 func translateFlags(args []string) []string {
 	var fdArgs []string
 	var pattern string
@@ -3,6 +3,9 @@ package find2fd
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
+	"github.com/kluzzebass/reflag/translator"
 )
 
 func TestTranslateFlags(t *testing.T) {
@@ -449,3 +452,143 @@ func TestTranslatorInterface(t *testing.T) {
 		t.Errorf("TargetTool() = %q, want %q", tr.TargetTool(), "fd")
 	}
 }
+
+func TestMappings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  translator.Fidelity
+	}{
+		{"name is exact", []string{".", "-name", "*.go"}, translator.Exact},
+		{"atime", []string{".", "-atime", "-7"}, translator.Approximate},
+		{"device type", []string{".", "-type", "b"}, translator.Approximate},
+		{"exact age", []string{".", "-mtime", "3"}, translator.Approximate},
+		{"max age is exact", []string{".", "-mtime", "-3"}, translator.Exact},
+		{"perm", []string{".", "-perm", "644", "-atime", "1"}, translator.Lossy},
+		{"exec", []string{".", "-name", "*.o", "-exec", "rm", "{}", ";"}, translator.Lossy},
+		{"unknown", []string{".", "-newermt", "2024-01-01"}, translator.Lossy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, mappings := translator.Grade(&Translator{}, tt.input, ""); got != tt.want {
+				t.Errorf("Grade(%q) = %v (%+v), want %v", tt.input, got, mappings, tt.want)
+			}
+		})
+	}
+}
+
+func TestSideEffect(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected string
+	}{
+		{[]string{".", "-name", "*.o"}, ""},
+		{[]string{".", "-name", "*.o", "-delete"}, "-delete deletes files"},
+		{[]string{".", "-exec", "rm", "{}", ";"}, "-exec runs commands"},
+		{[]string{".", "-fprint", "out"}, "-fprint writes to a file"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Translator{}).SideEffect(tt.input, ""); got != tt.expected {
+			t.Errorf("SideEffect(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+// busyboxUsage is busybox 1.36's find --help
+const busyboxUsage = `Usage: find [-HL] [PATH]... [OPTIONS] [ACTIONS]
+
+Search for files and perform actions on them.
+First failed action stops processing of current file.
+Defaults: PATH is current directory, action is '-print'
+
+	-L,-follow	Follow symlinks
+	-H		...on command line only
+	-xdev		Don't descend directories on other filesystems
+	-maxdepth N	Descend at most N levels. -maxdepth 0 applies
+			actions to command line arguments only
+	-mindepth N	Don't act on first N levels
+	-depth		Act on directory *after* traversing it
+
+Actions:
+	( ACTIONS )	Group actions for -o / -a
+	! ACT		Invert ACT's success/failure
+	ACT1 [-a] ACT2	If ACT1 fails, stop, else do ACT2
+	ACT1 -o ACT2	If ACT1 succeeds, stop, else do ACT2
+			Note: -a has higher priority than -o
+	-name PATTERN	Match file name (w/o directory name) to PATTERN
+			Backslash escapes special chars in PATTERN
+	-iname PATTERN	Case insensitive -name
+	-path PATTERN	Match path to PATTERN
+	-ipath PATTERN	Case insensitive -path
+	-regex PATTERN	Match path to regex PATTERN
+	-type X		File type is X (one of: f,d,l,b,c,s,p)
+	-executable	File is executable
+	-perm MASK	At least one mask bit (+MASK), all bits (-MASK),
+			or exactly MASK bits are set in file's mode
+	-mtime DAYS	mtime is greater than (+N), less than (-N),
+			or exactly N days in the past
+	-mmin MINS	mtime is greater than (+N), less than (-N),
+			or exactly N minutes in the past
+	-newer FILE	mtime is more recent than FILE's
+	-inum N		File has inode number N
+	-samefile FILE	File is same as FILE
+	-user NAME/ID	File is owned by given user
+	-group NAME/ID	File is owned by given group
+	-size N[bck]	File size is N (c:bytes,k:kbytes,b:512 bytes(def.))
+			+/-N: file size is bigger/smaller than N
+	-links N	Number of links is greater than (+N), less than (-N),
+			or exactly N
+	-prune		If current file is directory, don't descend into it
+	-empty		Match empty file/directory
+If none of the following actions is specified, -print is assumed
+	-print		Print file name
+	-print0		Print file name, NUL terminated
+	-exec CMD ARG ;	Run CMD with all instances of {} replaced by
+			file name. Fails if CMD exits with nonzero
+	-exec CMD ARG + Run CMD with {} replaced by list of file names
+	-delete		Delete current file/directory. Turns on -depth option
+	-quit		Exit
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := append([]string{"."}, o.Args("1")...)
+		if o.Name == "-exec" {
+			args = []string{".", "-exec", "echo", "{}", ";"}
+		}
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox find %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD primaries busybox find rejects
+	for _, args := range [][]string{
+		{"-atime", "1"}, {"-printf", "%p"}, {"-iregex", "x"}, {"-mount"}, {"-execdir", "rm", "{}", ";"}, {"-ls"}, {"-true"},
+	} {
+		if got := usage.Rejected(tr.Mappings(append([]string{"."}, args...), "busybox")); len(got) != 1 {
+			t.Errorf("busybox find %q: rejected = %q, want %s", args, got, args[0])
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{".", "-name", "*.go", "-type", "f"}, []string{"-t", "f", "\\.go$"}},
+		{[]string{"src", "-printf", "%p\\n", "-name", "*.go"}, []string{"\\.go$", "src"}},
+		{[]string{".", "-mmin", "-5", "-atime", "1"}, []string{"--changed-within", "5min"}},
+		{[]string{"-L", "src", "-links", "2"}, []string{"-L", ".", "src"}},
+		{[]string{".", "-execdir", "rm", "{}", ";", "-empty"}, []string{"-t", "e"}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
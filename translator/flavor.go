@@ -0,0 +1,45 @@
+package translator
+
+import (
+	"slices"
+	"strings"
+)
+
+// Flavored is implemented by translators whose translation depends on the
+// source tool's dialect, such as BSD or GNU ls. The mode passed to Translate
+// names the dialect; an empty mode means the installed tool's.
+type Flavored interface {
+	// Flavors lists the modes the translator knows, e.g. "bsd" and "gnu"
+	Flavors() []string
+}
+
+// FlavorDetector reports the dialect of an installed source tool, or ""
+// when it can't tell. reflag sets it to flavor.Detect; when it's nil,
+// nothing is detected and translators fall back to their own default.
+var FlavorDetector func(tool string) string
+
+// DetectFlavor returns the dialect of the installed tool, or "" when there
+// is no FlavorDetector or it can't tell
+func DetectFlavor(tool string) string {
+	if FlavorDetector == nil {
+		return ""
+	}
+	return FlavorDetector(tool)
+}
+
+// ResolveMode returns the mode t translates with: mode itself when given,
+// else the detected dialect of t's source tool if t knows it, else "". It
+// is lowercased, as modes are matched without case.
+func ResolveMode(t Translator, mode string) string {
+	if mode != "" {
+		return strings.ToLower(mode)
+	}
+	f, ok := t.(Flavored)
+	if !ok {
+		return ""
+	}
+	if detected := DetectFlavor(t.SourceTool()); slices.Contains(f.Flavors(), detected) {
+		return detected
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package translator
+
+import "testing"
+
+// flavoredMock is a mockTranslator that knows BSD and GNU modes
+type flavoredMock struct {
+	mockTranslator
+}
+
+func (m *flavoredMock) Flavors() []string { return []string{"bsd", "gnu"} }
+
+func TestResolveMode(t *testing.T) {
+	detected := map[string]string{"ls": "bsd", "grep": "busybox"}
+	FlavorDetector = func(tool string) string { return detected[tool] }
+	t.Cleanup(func() { FlavorDetector = nil })
+
+	tests := []struct {
+		name string
+		t    Translator
+		mode string
+		want string
+	}{
+		{"given mode wins", &flavoredMock{mockTranslator{source: "ls"}}, "GNU", "gnu"},
+		{"detected", &flavoredMock{mockTranslator{source: "ls"}}, "", "bsd"},
+		{"detected but unknown", &flavoredMock{mockTranslator{source: "grep"}}, "", ""},
+		{"not detected", &flavoredMock{mockTranslator{source: "find"}}, "", ""},
+		{"not flavored", &mockTranslator{source: "ls"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveMode(tt.t, tt.mode); got != tt.want {
+				t.Errorf("ResolveMode(%q) = %q, want %q", tt.mode, got, tt.want)
+			}
+		})
+	}
+
+	FlavorDetector = nil
+	if got := ResolveMode(&flavoredMock{mockTranslator{source: "ls"}}, ""); got != "" {
+		t.Errorf("ResolveMode without a detector = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,179 @@
+package translator
+
+import (
+	"slices"
+	"strings"
+)
+
+// Getopt describes a tool's options in getopt_long(3) terms, for
+// translators that must accept exactly the options one implementation of
+// the source tool does, such as busybox's
+type Getopt struct {
+	// Short lists the option letters as getopt's optstring does: a letter
+	// followed by ':' takes a value, attached (-w80) or as the next
+	// argument (-w 80)
+	Short string
+
+	// Long lists the long option names without "--". A name followed by
+	// ':' takes a value (--name=value or --name value), by '::' an
+	// optional one (--name=value only).
+	Long []string
+
+	// Exact turns off abbreviated long options, for describing the options
+	// a translator recognises rather than the ones a tool accepts
+	Exact bool
+}
+
+// Option is one option parsed by Getopt.Parse
+type Option struct {
+	Name     string // "-w" or "--color"
+	Value    string
+	HasValue bool
+}
+
+// String returns the option as a single argument: -w80 or --color=always
+func (o Option) String() string {
+	switch {
+	case !o.HasValue:
+		return o.Name
+	case strings.HasPrefix(o.Name, "--"):
+		return o.Name + "=" + o.Value
+	}
+	return o.Name + o.Value
+}
+
+// OptionArgs returns opts as arguments, one per option; see Option.String
+func OptionArgs(opts []Option) []string {
+	args := make([]string, 0, len(opts))
+	for _, o := range opts {
+		args = append(args, o.String())
+	}
+	return args
+}
+
+// Parse splits args into options and operands as getopt_long does, with
+// GNU argument permutation: options may follow operands, up to "--". A
+// value is taken even when it looks like an option (-e -x). Long options
+// may be abbreviated to any unambiguous prefix. Options g doesn't have, and
+// long options given a value they don't take or lacking one they need,
+// are returned in unknown as given; the tool would reject them.
+func (g Getopt) Parse(args []string) (opts []Option, operands, unknown []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return opts, append(operands, args[i+1:]...), unknown
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			long, ok := g.long(name)
+			if !ok {
+				unknown = append(unknown, "--"+name)
+				continue
+			}
+			o := Option{Name: "--" + strings.TrimRight(long, ":")}
+			switch {
+			case strings.HasSuffix(long, "::"):
+				o.Value, o.HasValue = value, hasValue
+			case strings.HasSuffix(long, ":"):
+				if !hasValue {
+					if i+1 >= len(args) {
+						unknown = append(unknown, arg)
+						continue
+					}
+					i++
+					value = args[i]
+				}
+				o.Value, o.HasValue = value, true
+			case hasValue:
+				unknown = append(unknown, arg)
+				continue
+			}
+			opts = append(opts, o)
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for j := 1; j < len(arg); j++ {
+				c := arg[j]
+				k := strings.IndexByte(g.Short, c)
+				if c == ':' || k < 0 {
+					unknown = append(unknown, "-"+string(c))
+					continue
+				}
+				if k+1 >= len(g.Short) || g.Short[k+1] != ':' {
+					opts = append(opts, Option{Name: "-" + string(c)})
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						unknown = append(unknown, "-"+string(c))
+						break
+					}
+					i++
+					value = args[i]
+				}
+				opts = append(opts, Option{Name: "-" + string(c), Value: value, HasValue: true})
+				break
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return opts, operands, unknown
+}
+
+// long finds the long option name, or the only one it abbreviates, with its
+// ':' suffix
+func (g Getopt) long(name string) (string, bool) {
+	match := ""
+	for _, l := range g.Long {
+		base := strings.TrimRight(l, ":")
+		if base == name {
+			return l, true
+		}
+		if name != "" && !g.Exact && strings.HasPrefix(base, name) {
+			if match != "" {
+				return "", false
+			}
+			match = l
+		}
+	}
+	return match, match != ""
+}
+
+// Operands returns operands to follow the translated options, after "--"
+// when one of them would otherwise be read as an option
+func Operands(operands []string) []string {
+	for _, op := range operands {
+		if strings.HasPrefix(op, "-") && op != "-" {
+			return append([]string{"--"}, operands...)
+		}
+	}
+	return operands
+}
+
+// Unsupported grades options the source tool doesn't have, such as GNU
+// options given to busybox: the source command would fail, and the
+// translation drops them
+func Unsupported(flavor, tool string, options []string) []Mapping {
+	var mappings []Mapping
+	for _, o := range options {
+		mappings = append(mappings, Mapping{Flag: o, Fidelity: Lossy, Note: flavor + " " + tool + " has no such option; it is dropped"})
+	}
+	return mappings
+}
+
+// Unknown adds to mappings a Lossy grade for each of flags, the ones the
+// translator doesn't recognise, that mappings doesn't grade already. Such
+// flags are passed on or dropped untranslated, so whatever they mean to tool
+// may be lost. Long flags are graded without any =value, as MapFlags does.
+func Unknown(mappings []Mapping, tool string, flags []string) []Mapping {
+	for _, f := range flags {
+		if strings.HasPrefix(f, "--") {
+			f, _, _ = strings.Cut(f, "=")
+		}
+		if slices.ContainsFunc(mappings, func(m Mapping) bool { return m.Flag == f }) {
+			continue
+		}
+		mappings = append(mappings, Mapping{Flag: f, Fidelity: Lossy, Note: "not a " + tool + " flag reflag knows; it is not translated"})
+	}
+	return mappings
+}
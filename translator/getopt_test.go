@@ -0,0 +1,54 @@
+package translator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetoptParse(t *testing.T) {
+	g := Getopt{Short: "alw:e:", Long: []string{"color::", "full-time", "group-directories-first", "width:"}}
+	tests := []struct {
+		name     string
+		args     []string
+		opts     []string
+		operands []string
+		unknown  []string
+	}{
+		{"bundled", []string{"-la", "dir"}, []string{"-l", "-a"}, []string{"dir"}, nil},
+		{"attached value", []string{"-lw80"}, []string{"-l", "-w80"}, nil, nil},
+		{"separate value", []string{"-w", "80", "dir"}, []string{"-w80"}, []string{"dir"}, nil},
+		{"value like an option", []string{"-e", "-x"}, []string{"-e-x"}, nil, nil},
+		{"permuted", []string{"dir", "-l"}, []string{"-l"}, []string{"dir"}, nil},
+		{"double dash", []string{"-l", "--", "-a"}, []string{"-l"}, []string{"-a"}, nil},
+		{"lone dash", []string{"-"}, nil, []string{"-"}, nil},
+		{"unknown letters", []string{"-lGT"}, []string{"-l"}, nil, []string{"-G", "-T"}},
+		{"missing value", []string{"-w"}, nil, nil, []string{"-w"}},
+		{"optional long value", []string{"--color", "--color=never"}, []string{"--color", "--color=never"}, nil, nil},
+		{"required long value", []string{"--width", "80", "--width=90"}, []string{"--width=80", "--width=90"}, nil, nil},
+		{"abbreviated", []string{"--full"}, []string{"--full-time"}, nil, nil},
+		{"unknown long", []string{"--almost-all", "--fu=1"}, nil, nil, []string{"--almost-all", "--fu=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, operands, unknown := g.Parse(tt.args)
+			if got := OptionArgs(opts); !slices.Equal(got, tt.opts) && len(got)+len(tt.opts) > 0 {
+				t.Errorf("options = %q, want %q", got, tt.opts)
+			}
+			if !slices.Equal(operands, tt.operands) {
+				t.Errorf("operands = %q, want %q", operands, tt.operands)
+			}
+			if !slices.Equal(unknown, tt.unknown) {
+				t.Errorf("unknown = %q, want %q", unknown, tt.unknown)
+			}
+		})
+	}
+}
+
+func TestOperands(t *testing.T) {
+	if got := Operands([]string{"a", "-"}); !slices.Equal(got, []string{"a", "-"}) {
+		t.Errorf("Operands(a, -) = %q", got)
+	}
+	if got := Operands([]string{"a", "-b"}); !slices.Equal(got, []string{"--", "a", "-b"}) {
+		t.Errorf("Operands(a, -b) = %q", got)
+	}
+}
@@ -1,6 +1,7 @@
 package grep2rg
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/kluzzebass/reflag/translator"
@@ -18,11 +19,167 @@ func (t *Translator) SourceTool() string  { return "grep" }
 func (t *Translator) TargetTool() string  { return "rg" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Searches with ripgrep, a faster grep that skips ignored files",
+		Homepage:    "https://github.com/BurntSushi/ripgrep",
+		Tags:        []string{"search"},
+		MinVersion:  "13.0.0",
+		Install: map[string]string{
+			"apt":    "ripgrep",
+			"dnf":    "ripgrep",
+			"pacman": "ripgrep",
+			"brew":   "ripgrep",
+			"cargo":  "ripgrep",
+		},
+	}
+}
+
+// Flavors lists the grep dialects the translator knows; see
+// translator.Flavored. GNU and BSD options are told apart without a mode.
+func (t *Translator) Flavors() []string { return []string{"busybox"} }
+
 // Translate converts grep arguments to ripgrep arguments
 func (t *Translator) Translate(args []string, mode string) []string {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, operands, _ := busyboxOptions.Parse(args)
+		return translateBusybox(opts, operands)
+	}
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, _, unknown := busyboxOptions.Parse(args)
+		return append(translator.MapFlags(busyboxArgs(opts), fidelity), translator.Unsupported("busybox", "grep", unknown)...)
+	}
+	_, _, unknown := knownOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, fidelity), "grep", unknown)
+}
+
+// busyboxOptions are the options busybox grep accepts
+var busyboxOptions = translator.Getopt{Short: "lnqvscFiHhe:f:LorRm:wxEA:B:C:z"}
+
+// knownOptions are the options translateFlags recognises; others pass
+// through to rg untranslated
+var knownOptions = func() translator.Getopt {
+	g := translator.Getopt{
+		Short: "Z",
+		Long: []string{
+			"color::", "colour::", "null", "null-data", "label::",
+			"include:", "exclude:", "exclude-dir:", "regexp:", "file:",
+			"binary-files:", "directories:", "devices:",
+		},
+		Exact: true,
+	}
+	for c := range passthroughFlags {
+		g.Short += string(c)
+	}
+	for c := range ignoredFlags {
+		g.Short += string(c)
+	}
+	for c := range passthroughWithValue {
+		g.Short += string(c) + ":"
+	}
+	for c := range ignoredWithValue {
+		g.Short += string(c) + ":"
+	}
+	for flag := range longPassthrough {
+		if longWithValue[flag] {
+			flag += ":"
+		}
+		g.Long = append(g.Long, flag[2:])
+	}
+	for flag := range longIgnored {
+		g.Long = append(g.Long, flag[2:])
+	}
+	return g
+}()
+
+// translateBusybox translates options parsed as busybox grep does. The
+// first operand is the pattern unless -e or -f gave one, and -z reads
+// NUL-terminated input as GNU's --null-data does.
+func translateBusybox(opts []translator.Option, operands []string) []string {
+	args := busyboxArgs(opts)
+	if !slices.ContainsFunc(opts, func(o translator.Option) bool { return o.Name == "-e" || o.Name == "-f" }) && len(operands) > 0 {
+		args = append(args, "-e"+operands[0])
+		operands = operands[1:]
+	}
+	out := translateFlags(args)
+	if slices.Contains(out, "--") {
+		return append(out, operands...)
+	}
+	return append(out, translator.Operands(operands)...)
+}
+
+// busyboxArgs returns busybox grep options as GNU grep arguments
+func busyboxArgs(opts []translator.Option) []string {
+	args := translator.OptionArgs(opts)
+	for i, arg := range args {
+		if arg == "-z" {
+			args[i] = "--null-data"
+		}
+	}
+	return args
+}
+
+// Table returns the flags that pass through or are ignored whatever their
+// position; see translator.Tabled. Flags that take a value are left out.
+func (t *Translator) Table() translator.Table {
+	table := translator.Table{
+		Short: map[rune][]string{'Z': {"-0"}},
+		Long:  map[string][]string{"--null": {"-0"}, "--null-data": {"-0"}},
+	}
+	for c := range passthroughFlags {
+		table.Short[c] = []string{"-" + string(c)}
+	}
+	for c := range ignoredFlags {
+		table.Short[c] = []string{}
+	}
+	for flag := range longPassthrough {
+		if !longWithValue[flag] {
+			table.Long[flag] = []string{flag}
+		}
+	}
+	for flag := range longIgnored {
+		if flag != "--binary-files" && flag != "--directories" && flag != "--devices" {
+			table.Long[flag] = []string{}
+		}
+	}
+	return table
+}
+
+// Long flags in longPassthrough that take a value
+var longWithValue = map[string]bool{
+	"--max-count":      true,
+	"--after-context":  true,
+	"--before-context": true,
+	"--context":        true,
+}
+
+// Flags that don't translate exactly. Several grep short flags pass
+// through to rg flags of the same letter with a different meaning.
+var fidelity = map[string]translator.Mapping{
+	"-G":             {Fidelity: translator.Approximate, Note: "patterns are read as extended regular expressions"},
+	"--basic-regexp": {Fidelity: translator.Approximate, Note: "patterns are read as extended regular expressions"},
+	"-d":             {Fidelity: translator.Approximate, Note: "rg always recurses into directories"},
+	"--directories":  {Fidelity: translator.Approximate, Note: "rg always recurses into directories"},
+	"-D":             {Fidelity: translator.Approximate, Note: "rg decides how to read devices"},
+	"--devices":      {Fidelity: translator.Approximate, Note: "rg decides how to read devices"},
+	"--binary-files": {Fidelity: translator.Approximate, Note: "rg decides how to treat binary files"},
+	"-b":             {Fidelity: translator.Lossy, Note: "byte offsets are not printed"},
+	"--byte-offset":  {Fidelity: translator.Lossy, Note: "passed through; rg prints byte offsets differently"},
+	"-T":             {Fidelity: translator.Lossy, Note: "lines are not aligned with tabs"},
+	"--initial-tab":  {Fidelity: translator.Lossy, Note: "passed through; rg has no such flag"},
+	"-h":             {Fidelity: translator.Lossy, Note: "rg -h prints help instead of hiding file names"},
+	"-L":             {Fidelity: translator.Lossy, Note: "rg -L follows symlinks instead of listing files without matches"},
+	"-s":             {Fidelity: translator.Lossy, Note: "rg -s searches case sensitively; errors are not suppressed"},
+	"-U":             {Fidelity: translator.Lossy, Note: "rg -U enables multiline matching"},
+	"--null-data":    {Fidelity: translator.Lossy, Note: "input is read as lines; rg -0 only changes the output"},
+}
+
 // Flags that pass through unchanged (same in grep and rg)
 var passthroughFlags = map[rune]bool{
 	'i': true, // case insensitive
@@ -63,15 +220,21 @@ var ignoredFlags = map[rune]bool{
 	'I': true, // skip binary (rg default)
 	'b': true, // byte offset (rg has it but different)
 	'T': true, // initial tab
-	'd': true, // directory handling
-	'D': true, // device handling
 	'u': true, // unix byte offsets
 }
 
+// Flags to ignore along with their value
+var ignoredWithValue = map[rune]bool{
+	'd': true, // directory handling (ACTION)
+	'D': true, // device handling (ACTION)
+}
+
 // Long flags that pass through
 var longPassthrough = map[string]bool{
 	"--color":               true,
 	"--colour":              true,
+	"--ignore-case":         true,
+	"--no-ignore-case":      true,
 	"--line-number":         true,
 	"--with-filename":       true,
 	"--no-filename":         true,
@@ -187,6 +350,11 @@ func translateFlags(args []string) []string {
 						rgArgs = append(rgArgs, "-g", "!"+val)
 					}
 				}
+			case "--binary-files", "--directories", "--devices":
+				// Ignored, along with their value
+				if i+1 < len(args) {
+					skipNext = true
+				}
 			case "--regexp":
 				if i+1 < len(args) {
 					patterns = append(patterns, args[i+1])
@@ -251,6 +419,14 @@ func translateFlags(args []string) []string {
 					continue
 				}
 
+				if ignoredWithValue[c] {
+					// The rest of the bundle, or the next arg, is the value
+					if j == len(flags)-1 && i+1 < len(args) {
+						skipNext = true
+					}
+					break
+				}
+
 				// Unknown flag - pass through
 				rgArgs = append(rgArgs, "-"+string(c))
 			}
@@ -3,6 +3,9 @@ package grep2rg
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
+	"github.com/kluzzebass/reflag/translator"
 )
 
 func TestTranslateFlags(t *testing.T) {
@@ -91,6 +94,16 @@ func TestTranslateFlags(t *testing.T) {
 			input:    []string{"-rni", "pattern", "."},
 			expected: []string{"-n", "-i", "pattern", "."},
 		},
+		{
+			name:     "directories ignored with value",
+			input:    []string{"-d", "skip", "pattern", "."},
+			expected: []string{"pattern", "."},
+		},
+		{
+			name:     "devices ignored with attached value",
+			input:    []string{"-iDread", "pattern"},
+			expected: []string{"-i", "pattern"},
+		},
 
 		// Context flags
 		{
@@ -214,6 +227,11 @@ func TestTranslateFlags(t *testing.T) {
 			input:    []string{"--extended-regexp", "pattern"},
 			expected: []string{"pattern"},
 		},
+		{
+			name:     "long directories ignored with value",
+			input:    []string{"--directories", "skip", "pattern"},
+			expected: []string{"pattern"},
+		},
 
 		// Pattern starting with dash
 		{
@@ -265,3 +283,94 @@ func TestTranslatorInterface(t *testing.T) {
 		t.Errorf("TargetTool() = %q, want %q", tr.TargetTool(), "rg")
 	}
 }
+
+func TestMappings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		want  translator.Fidelity
+	}{
+		{"case insensitive", []string{"-i", "foo"}, translator.Exact},
+		{"bundle", []string{"-rni", "foo", "."}, translator.Exact},
+		{"no messages", []string{"-is", "foo"}, translator.Lossy},
+		{"after double dash", []string{"--", "-s"}, translator.Exact},
+		{"context value", []string{"-A3", "--max-count", "2", "foo"}, translator.Exact},
+		{"unknown flags", []string{"--frobnicate", "-Y", "foo"}, translator.Lossy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, mappings := translator.Grade(&Translator{}, tt.input, ""); got != tt.want {
+				t.Errorf("Grade(%q) = %v (%+v), want %v", tt.input, got, mappings, tt.want)
+			}
+		})
+	}
+}
+
+// busyboxUsage is busybox 1.36's grep --help
+const busyboxUsage = `Usage: grep [-HhnlLoqvsrRiwFExz] [-m N] [-A|B|C N] { PATTERN | -e PATTERN... | -f FILE... } [FILE]...
+
+Search for PATTERN in FILEs (or stdin)
+
+	-H	Add 'filename:' prefix
+	-h	Do not add 'filename:' prefix
+	-n	Add 'line_no:' prefix
+	-l	Show only names of files that match
+	-L	Show only names of files that don't match
+	-c	Show only count of matching lines
+	-o	Show only the matching part of line
+	-q	Quiet. Return 0 if PATTERN is found, 1 otherwise
+	-v	Select non-matching lines
+	-s	Suppress open and read errors
+	-r	Recurse
+	-R	Recurse and dereference symlinks
+	-i	Ignore case
+	-w	Match whole words only
+	-x	Match whole lines only
+	-F	PATTERN is a literal (not regexp)
+	-E	PATTERN is an extended regexp
+	-z	NUL terminated input
+	-m N	Match up to N times per file
+	-A N	Print N lines of trailing context
+	-B N	Print N lines of leading context
+	-C N	Same as '-A N -B N'
+	-e PTRN	Pattern to match
+	-f FILE	Read pattern from file
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := append(o.Args("2"), "pattern")
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox grep %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD options busybox grep rejects
+	for _, arg := range []string{"-P", "-a", "-I", "-b", "-Z", "-G", "--include=*.go", "--color=auto"} {
+		if got := usage.Rejected(tr.Mappings([]string{arg, "pattern"}, "busybox")); len(got) != 1 {
+			t.Errorf("busybox grep %s: rejected = %q, want it", arg, got)
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{"-rn", "foo", "."}, []string{"-n", "foo", "."}},
+		{[]string{"foo", "-i", "file"}, []string{"-i", "foo", "file"}},
+		{[]string{"-e", "-x", "file"}, []string{"--", "-x", "file"}},
+		{[]string{"-A2", "-m", "3", "foo"}, []string{"-A", "2", "-m", "3", "foo"}},
+		{[]string{"-z", "foo"}, []string{"-0", "foo"}},
+		{[]string{"-Pi", "foo", "--", "-file"}, []string{"-i", "foo", "--", "-file"}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -18,11 +18,92 @@ func (t *Translator) SourceTool() string  { return "less" }
 func (t *Translator) TargetTool() string  { return "moor" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Pages with moor, a less that works out of the box",
+		Homepage:    "https://github.com/walles/moor",
+		Tags:        []string{"pagers"},
+		MinVersion:  "2.0.0",
+		Install: map[string]string{
+			"brew": "moor",
+		},
+	}
+}
+
 // Translate converts less arguments to moor arguments
 func (t *Translator) Translate(args []string, mode string) []string {
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	_, _, unknown := knownOptions.Parse(args)
+	mappings := translator.Unknown(translator.MapFlags(args, fidelity), "less", unknown)
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) > 1 && arg[0] == '+' && (arg[1] < '0' || arg[1] > '9') {
+			mappings = append(mappings, translator.Mapping{Flag: arg, Fidelity: translator.Lossy, Note: "only +NUMBER start commands are supported"})
+		}
+	}
+	return mappings
+}
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-e":                    {Fidelity: translator.Approximate, Note: "moor quits when the file fits on one screen, not at the second end of file"},
+	"-i":                    {Fidelity: translator.Approximate, Note: "moor handles search case and highlighting itself"},
+	"-I":                    {Fidelity: translator.Approximate, Note: "moor handles search case and highlighting itself"},
+	"-g":                    {Fidelity: translator.Approximate, Note: "moor handles search case and highlighting itself"},
+	"-G":                    {Fidelity: translator.Approximate, Note: "moor handles search case and highlighting itself"},
+	"-P":                    {Fidelity: translator.Approximate, Note: "moor uses its own prompt"},
+	"-m":                    {Fidelity: translator.Approximate, Note: "moor uses its own prompt"},
+	"-M":                    {Fidelity: translator.Approximate, Note: "moor uses its own prompt"},
+	"-j":                    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"-z":                    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"-y":                    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"-h":                    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"-J":                    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"--window":              {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"--max-forw-scroll":     {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"--line-num-width":      {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"--status-col-width":    {Fidelity: translator.Approximate, Note: "moor lays out the screen its own way"},
+	"-s":                    {Fidelity: translator.Lossy, Note: "blank lines are not squeezed"},
+	"--squeeze-blank-lines": {Fidelity: translator.Lossy, Note: "blank lines are not squeezed"},
+	"-p":                    {Fidelity: translator.Lossy, Note: "the pattern is not searched for on start"},
+	"--pattern":             {Fidelity: translator.Lossy, Note: "the pattern is not searched for on start"},
+	"-t":                    {Fidelity: translator.Lossy, Note: "tags are not used"},
+	"-T":                    {Fidelity: translator.Lossy, Note: "tags are not used"},
+	"--tag":                 {Fidelity: translator.Lossy, Note: "tags are not used"},
+	"--tag-file":            {Fidelity: translator.Lossy, Note: "tags are not used"},
+	"-k":                    {Fidelity: translator.Lossy, Note: "lesskey files are not read"},
+	"-o":                    {Fidelity: translator.Lossy, Note: "no log file is written"},
+	"-O":                    {Fidelity: translator.Lossy, Note: "no log file is written"},
+	"--log-file":            {Fidelity: translator.Lossy, Note: "no log file is written"},
+}
+
+// knownOptions are the options translateFlags recognises; it passes other
+// long options through to moor and drops other short ones
+var knownOptions = func() translator.Getopt {
+	g := translator.Getopt{Short: "x:", Exact: true}
+	for c := range flagMap {
+		g.Short += string(c)
+		if strings.ContainsRune("tTpPoOkDbhjyz#", c) {
+			g.Short += ":"
+		}
+	}
+	// Prefixes first, so --tabs=4 finds the one taking a value
+	for _, prefix := range longFlagPrefixes {
+		g.Long = append(g.Long, strings.TrimSuffix(prefix[2:], "=")+"::")
+	}
+	for flag := range longFlagMap {
+		g.Long = append(g.Long, flag[2:])
+	}
+	return g
+}()
+
 // Simple 1:1 flag mappings from less to moor
 var flagMap = map[rune][]string{
 	// Display options
@@ -1,7 +1,9 @@
 package ls2eza
 
 import (
+	"maps"
 	"runtime"
+	"slices"
 	"strings"
 
 	"github.com/kluzzebass/reflag/translator"
@@ -19,9 +21,164 @@ func (t *Translator) SourceTool() string  { return "ls" }
 func (t *Translator) TargetTool() string  { return "eza" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Lists directories with eza, a modern ls",
+		Homepage:    "https://github.com/eza-community/eza",
+		Tags:        []string{"files"},
+		MinVersion:  "0.18.0",
+		Install: map[string]string{
+			"apt":    "eza",
+			"dnf":    "eza",
+			"pacman": "eza",
+			"brew":   "eza",
+			"cargo":  "eza",
+		},
+	}
+}
+
+// Flavors lists the ls dialects the translator knows; see
+// translator.Flavored
+func (t *Translator) Flavors() []string { return []string{"bsd", "gnu", "busybox"} }
+
 // Translate converts ls arguments to eza arguments
 func (t *Translator) Translate(args []string, mode string) []string {
-	return translateFlags(args, getLSMode(mode))
+	lsMode := getLSMode(translator.ResolveMode(t, mode))
+	if lsMode == ModeBusybox {
+		opts, operands, _ := busyboxOptions.Parse(args)
+		return append(translateFlags(translator.OptionArgs(opts), ModeGNU), translator.Operands(operands)...)
+	}
+	return translateFlags(args, lsMode)
+}
+
+// TranslatePiped translates for output read by another program; see
+// translator.Piped. Listings of names get -1, as ls writes one name per line
+// to a pipe; long, grid and tree listings have no compatible form.
+func (t *Translator) TranslatePiped(args []string, mode string) ([]string, bool) {
+	out := t.Translate(args, mode)
+	for _, arg := range out {
+		switch arg {
+		case "-l", "--long", "--grid", "--across", "-T", "--tree":
+			return nil, false
+		case "-1", "--oneline":
+			return out, true
+		}
+	}
+	return append([]string{"-1"}, out...), true
+}
+
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	switch getLSMode(translator.ResolveMode(t, mode)) {
+	case ModeBSD:
+		_, _, unknown := bsdOptions.Parse(args)
+		return translator.Unknown(translator.MapFlags(args, bsdFidelity), "ls", unknown)
+	case ModeBusybox:
+		opts, _, unknown := busyboxOptions.Parse(args)
+		return append(translator.MapFlags(translator.OptionArgs(opts), gnuFidelity), translator.Unsupported("busybox", "ls", unknown)...)
+	}
+	_, _, unknown := gnuOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, gnuFidelity), "ls", unknown)
+}
+
+// Table returns the flags translated without regard to mode or other
+// flags, and the reverse-sort rule; see translator.Tabled
+func (t *Translator) Table() translator.Table {
+	table := translator.Table{
+		Short:        maps.Clone(flagMap),
+		Long:         maps.Clone(longFlagMap),
+		ReverseFlags: []string{"-r", "--reverse"},
+		Reverse:      "--reverse",
+	}
+	for c := range reverseNeeded {
+		table.SortFlags = append(table.SortFlags, "-"+string(c))
+	}
+	slices.Sort(table.SortFlags)
+	return table
+}
+
+// busyboxOptions are the options busybox ls accepts, including the ones
+// its usage doesn't list: -g, -k, -Q and -T WIDTH. They mean what they do
+// in GNU ls.
+var busyboxOptions = translator.Getopt{
+	Short: "Cadi1lgnsxAkFpRQctuSXrvLHhT:w:",
+	Long:  []string{"full-time", "group-directories-first", "color::"},
+}
+
+// gnuOptions and bsdOptions are the options translateFlags recognises in
+// each mode; others pass through to eza untranslated
+var (
+	gnuOptions = knownOptions("rDXI:w:T:")
+	bsdOptions = knownOptions("rIwXTD:")
+)
+
+// knownOptions returns the options in flagMap, longFlagMap and
+// longFlagPrefixes, along with the short options in extra that
+// translateFlags handles itself
+func knownOptions(extra string) translator.Getopt {
+	short := extra
+	for c := range flagMap {
+		short += string(c)
+	}
+	// Prefixes first, so --hyperlink=always finds the one taking a value
+	var long []string
+	for _, pf := range longFlagPrefixes {
+		long = append(long, strings.TrimSuffix(pf.prefix[2:], "=")+"::")
+	}
+	for flag := range longFlagMap {
+		long = append(long, flag[2:])
+	}
+	long = append(long, "reverse")
+	return translator.Getopt{Short: short, Long: long, Exact: true}
+}
+
+// Flags that don't translate exactly in either mode
+var fidelity = map[string]translator.Mapping{
+	"-q":                   {Fidelity: translator.Lossy, Note: "non-printable characters are not replaced with ?"},
+	"-b":                   {Fidelity: translator.Lossy, Note: "C-style escapes are not shown"},
+	"--escape":             {Fidelity: translator.Lossy, Note: "C-style escapes are not shown"},
+	"-e":                   {Fidelity: translator.Lossy, Note: "ACLs are not shown"},
+	"-Q":                   {Fidelity: translator.Lossy, Note: "names are not quoted"},
+	"--quote-name":         {Fidelity: translator.Lossy, Note: "names are not quoted"},
+	"--quoting-style":      {Fidelity: translator.Lossy, Note: "names are not quoted"},
+	"-W":                   {Fidelity: translator.Lossy, Note: "whiteouts are not shown"},
+	"--author":             {Fidelity: translator.Lossy, Note: "authors are not shown"},
+	"--hide":               {Fidelity: translator.Lossy, Note: "matching entries are not hidden"},
+	"--block-size":         {Fidelity: translator.Lossy, Note: "sizes are not scaled to the block size"},
+	"--indicator-style":    {Fidelity: translator.Lossy, Note: "no indicators are added"},
+	"--dired":              {Fidelity: translator.Lossy, Note: "no Emacs dired output"},
+	"--zero":               {Fidelity: translator.Lossy, Note: "lines are not NUL-terminated"},
+	"-k":                   {Fidelity: translator.Approximate, Note: "sizes are not shown in 1024-byte blocks"},
+	"--kibibytes":          {Fidelity: translator.Approximate, Note: "sizes are not shown in 1024-byte blocks"},
+	"--si":                 {Fidelity: translator.Approximate, Note: "sizes use eza's units"},
+	"-m":                   {Fidelity: translator.Approximate, Note: "one name per line instead of a comma-separated list"},
+	"-p":                   {Fidelity: translator.Approximate, Note: "every file type is marked, not just directories"},
+	"--file-type":          {Fidelity: translator.Approximate, Note: "every file type is marked, not just directories"},
+	"-v":                   {Fidelity: translator.Approximate, Note: "version sort is approximated by name sort"},
+	"-H":                   {Fidelity: translator.Approximate, Note: "all symlinks are followed, not just arguments"},
+	"--hide-control-chars": {Fidelity: translator.Approximate, Note: "eza decides how to show control characters"},
+	"--show-control-chars": {Fidelity: translator.Approximate, Note: "eza decides how to show control characters"},
+}
+
+// gnuFidelity and bsdFidelity add the flags whose meaning depends on the mode
+var (
+	gnuFidelity = withFidelity(fidelity, map[string]translator.Mapping{
+		"-B":               {Fidelity: translator.Lossy, Note: "backup files (~) are not hidden"},
+		"--ignore-backups": {Fidelity: translator.Lossy, Note: "backup files (~) are not hidden"},
+		"-D":               {Fidelity: translator.Lossy, Note: "no Emacs dired output"},
+	})
+	bsdFidelity = withFidelity(fidelity, map[string]translator.Mapping{
+		"-B": {Fidelity: translator.Lossy, Note: "octal escapes are not shown"},
+		"-T": {Fidelity: translator.Approximate, Note: "full timestamps are shown in ISO format"},
+	})
+)
+
+func withFidelity(base, extra map[string]translator.Mapping) map[string]translator.Mapping {
+	m := make(map[string]translator.Mapping, len(base)+len(extra))
+	maps.Copy(m, base)
+	maps.Copy(m, extra)
+	return m
 }
 
 // LSMode determines which ls flavor to emulate
@@ -30,18 +187,22 @@ type LSMode int
 const (
 	ModeBSD LSMode = iota
 	ModeGNU
+	ModeBusybox
 )
 
-// getLSMode returns the ls compatibility mode based on mode string or OS detection
+// getLSMode returns the ls compatibility mode for a mode string, guessing
+// from the OS when the mode is empty (the installed ls couldn't be probed)
 func getLSMode(mode string) LSMode {
 	switch strings.ToLower(mode) {
 	case "bsd":
 		return ModeBSD
 	case "gnu":
 		return ModeGNU
+	case "busybox":
+		return ModeBusybox
 	}
 
-	// Auto-detect based on OS
+	// Guess from the OS
 	switch runtime.GOOS {
 	case "darwin", "freebsd", "openbsd", "netbsd", "dragonfly":
 		return ModeBSD
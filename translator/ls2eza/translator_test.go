@@ -3,6 +3,9 @@ package ls2eza
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
+	"github.com/kluzzebass/reflag/translator"
 )
 
 func TestTranslateFlagsGNU(t *testing.T) {
@@ -452,6 +455,7 @@ func TestGetLSModeParameter(t *testing.T) {
 		{"GNU uppercase", "GNU", ModeGNU},
 		{"mixed case Bsd", "Bsd", ModeBSD},
 		{"mixed case Gnu", "Gnu", ModeGNU},
+		{"busybox", "busybox", ModeBusybox},
 	}
 
 	for _, tt := range tests {
@@ -484,3 +488,123 @@ func TestTranslatorInterface(t *testing.T) {
 		t.Errorf("Translate(-la) = %v, want %v", result, expected)
 	}
 }
+
+func TestMappings(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []string
+		mode  string
+		want  translator.Fidelity
+	}{
+		{"long listing", []string{"-la"}, "gnu", translator.Exact},
+		{"gnu -T", []string{"-lT", "8"}, "gnu", translator.Exact},
+		{"bsd -T", []string{"-lT"}, "bsd", translator.Approximate},
+		{"gnu -B", []string{"-B"}, "gnu", translator.Lossy},
+		{"bsd -B", []string{"-B"}, "bsd", translator.Lossy},
+		{"unknown short", []string{"-lZ9"}, "gnu", translator.Lossy},
+		{"unknown long", []string{"-l", "--bogus"}, "gnu", translator.Lossy},
+		{"gnu long in bsd", []string{"--color=auto", "--sort=size"}, "bsd", translator.Exact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, mappings := translator.Grade(&Translator{}, tt.input, tt.mode); got != tt.want {
+				t.Errorf("Grade(%q, %q) = %v (%+v), want %v", tt.input, tt.mode, got, mappings, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslatePiped(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []string
+		expected []string // nil when there is no compatible form
+	}{
+		{"names", []string{"-a"}, []string{"-1", "-a"}},
+		{"already one per line", []string{"-1t"}, []string{"-1", "--sort=modified", "--reverse"}},
+		{"long listing", []string{"-la"}, nil},
+		{"columns", []string{"-C"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := (&Translator{}).TranslatePiped(tt.input, "gnu")
+			if ok != (tt.expected != nil) || !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("TranslatePiped(%q) = %q, %v; want %q", tt.input, got, ok, tt.expected)
+			}
+		})
+	}
+}
+
+// busyboxUsage is busybox 1.36's ls --help
+const busyboxUsage = `Usage: ls [-1AaCxdLHRFplinshrSXvctu] [-w WIDTH] [FILE]...
+
+List directory contents
+
+	-1	One column output
+	-a	Include names starting with .
+	-A	Like -a, but exclude . and ..
+	-x	List by lines
+	-d	List directory names, not contents
+	-L	Follow symlinks
+	-H	Follow symlinks on command line
+	-R	Recurse
+	-p	Append / to directory names
+	-F	Append indicator (one of */=@|) to names
+	-l	Long format
+	-i	List inode numbers
+	-n	List numeric UIDs and GIDs instead of names
+	-s	List allocated blocks
+	-lc	List ctime
+	-lu	List atime
+	--full-time	List full date/time
+	-h	Human readable sizes (1K 243M 2G)
+	--group-directories-first
+	-S	Sort by size
+	-X	Sort by extension
+	-v	Sort by version
+	-t	Sort by mtime
+	-tc	Sort by ctime
+	-tu	Sort by atime
+	-r	Reverse sort order
+	-w N	Format N columns wide
+	--color[={always,never,auto}]
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := o.Args("80")
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox ls %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD options busybox ls rejects
+	for _, arg := range []string{"-B", "-G", "-I", "-D", "-U", "-m", "-o", "--almost-all", "--sort=size"} {
+		if got := usage.Rejected(tr.Mappings([]string{arg}, "busybox")); len(got) != 1 {
+			t.Errorf("busybox ls %s: rejected = %q, want it", arg, got)
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{"-ltr"}, []string{"-l", "--sort=modified"}},
+		{[]string{"-w", "80", "dir"}, []string{"--width=80", "dir"}},
+		{[]string{"dir", "-X"}, []string{"--sort=extension", "dir"}},
+		{[]string{"-T", "4", "-1"}, []string{"-1"}},
+		{[]string{"--full-time", "--color=never"}, []string{"-l", "--time-style=full-iso", "--color=never"}},
+		{[]string{"-lG", "--", "-file"}, []string{"-l", "--", "-file"}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package translator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Described is implemented by translators that describe themselves and
+// their target tool, for reflag list, the generated docs and reflag doctor
+type Described interface {
+	Metadata() Metadata
+}
+
+// Metadata describes a translator and the tool it targets
+type Metadata struct {
+	// Description is one line, e.g. "Lists directories with eza, a
+	// modern ls"
+	Description string `json:"description"`
+
+	// Homepage is the target project's URL
+	Homepage string `json:"homepage"`
+
+	// Tags name groups the translator belongs to, such as "pagers" or
+	// "search"; see Match
+	Tags []string `json:"tags"`
+
+	// MinVersion is the oldest target version the translation is written
+	// for, e.g. "0.18.0"; "" if any version works
+	MinVersion string `json:"min_version,omitempty"`
+
+	// Install maps package managers (see PackageManagers) to the name of
+	// the target's package
+	Install map[string]string `json:"install,omitempty"`
+
+	// Binaries maps package managers to the name their package installs
+	// the target as, where that isn't the target's name: Debian's fd-find
+	// installs fdfind
+	Binaries map[string]string `json:"binaries,omitempty"`
+}
+
+// PackageManagers lists the package managers install hints may name, in
+// the order they are shown
+var PackageManagers = []string{"brew", "apt", "dnf", "pacman", "cargo"}
+
+// Describe returns t's metadata. Translators that don't implement
+// Described get their tags (see Tagged) and nothing else.
+func Describe(t Translator) Metadata {
+	if d, ok := t.(Described); ok {
+		return d.Metadata()
+	}
+	if tg, ok := t.(Tagged); ok {
+		return Metadata{Tags: tg.Tags()}
+	}
+	return Metadata{}
+}
+
+// InstallCommand returns the command that installs pkg with manager, or ""
+// for an unknown manager
+func InstallCommand(manager, pkg string) string {
+	switch manager {
+	case "apt", "dnf":
+		return "sudo " + manager + " install " + pkg
+	case "pacman":
+		return "sudo pacman -S " + pkg
+	case "brew", "cargo":
+		return manager + " install " + pkg
+	}
+	return ""
+}
+
+// CompareVersions compares dotted version numbers such as 0.18.2 and
+// 3.3a numerically, component by component, ignoring a leading v and
+// anything after a component's digits. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		x, y := versionPart(as, i), versionPart(bs, i)
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionPart returns the leading number of parts[i], 0 if there is none
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	s := parts[i]
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
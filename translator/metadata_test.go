@@ -0,0 +1,70 @@
+package translator
+
+import (
+	"slices"
+	"testing"
+)
+
+// describedMock is a mockTranslator with metadata
+type describedMock struct {
+	mockTranslator
+	meta Metadata
+}
+
+func (m *describedMock) Metadata() Metadata { return m.meta }
+
+func TestDescribe(t *testing.T) {
+	described := &describedMock{mockTranslator{name: "a2b"}, Metadata{Description: "d", Tags: []string{"x"}}}
+	tagged := &taggedMock{mockTranslator{name: "c2d"}, []string{"y"}}
+	plain := &mockTranslator{name: "e2f"}
+
+	if m := Describe(described); m.Description != "d" || !slices.Equal(Tags(described), []string{"x"}) {
+		t.Errorf("Describe(described) = %+v", m)
+	}
+	if m := Describe(tagged); m.Description != "" || !slices.Equal(m.Tags, []string{"y"}) {
+		t.Errorf("Describe(tagged) = %+v", m)
+	}
+	if m := Describe(plain); m.Description != "" || m.Tags != nil {
+		t.Errorf("Describe(plain) = %+v", m)
+	}
+}
+
+func TestInstallCommand(t *testing.T) {
+	tests := []struct {
+		manager, want string
+	}{
+		{"apt", "sudo apt install fd-find"},
+		{"dnf", "sudo dnf install fd-find"},
+		{"pacman", "sudo pacman -S fd-find"},
+		{"brew", "brew install fd-find"},
+		{"cargo", "cargo install fd-find"},
+		{"zypper", ""},
+	}
+
+	for _, tt := range tests {
+		if got := InstallCommand(tt.manager, "fd-find"); got != tt.want {
+			t.Errorf("InstallCommand(%q) = %q, want %q", tt.manager, got, tt.want)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.18.0", "0.18.0", 0},
+		{"0.18", "0.18.0", 0},
+		{"v0.20.1", "0.18.0", 1},
+		{"0.9.9", "0.18.0", -1},
+		{"3.3a", "3.0", 1},
+		{"14.1.1", "13.0.0", 1},
+		{"2.0.0-rc1", "2.0.0", 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
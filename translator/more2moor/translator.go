@@ -18,11 +18,63 @@ func (t *Translator) SourceTool() string  { return "more" }
 func (t *Translator) TargetTool() string  { return "moor" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Pages with moor instead of more",
+		Homepage:    "https://github.com/walles/moor",
+		Tags:        []string{"pagers"},
+		MinVersion:  "2.0.0",
+		Install: map[string]string{
+			"brew": "moor",
+		},
+	}
+}
+
 // Translate converts more arguments to moor arguments
 func (t *Translator) Translate(args []string, mode string) []string {
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	_, _, unknown := knownOptions.Parse(args)
+	mappings := translator.Unknown(translator.MapFlags(args, fidelity), "more", unknown)
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if len(arg) > 1 && arg[0] == '+' && (arg[1] < '0' || arg[1] > '9') {
+			mappings = append(mappings, translator.Mapping{Flag: arg, Fidelity: translator.Lossy, Note: "only +NUMBER start commands are supported"})
+		}
+	}
+	return mappings
+}
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-n":        {Fidelity: translator.Approximate, Note: "the screen size is not set"},
+	"--lines":   {Fidelity: translator.Approximate, Note: "the screen size is not set"},
+	"-l":        {Fidelity: translator.Approximate, Note: "moor pages by screen lines"},
+	"-f":        {Fidelity: translator.Approximate, Note: "moor pages by screen lines"},
+	"-s":        {Fidelity: translator.Lossy, Note: "blank lines are not squeezed"},
+	"--squeeze": {Fidelity: translator.Lossy, Note: "blank lines are not squeezed"},
+}
+
+// knownOptions are the options translateFlags recognises, with -NUMBER as
+// digit flags; it passes other long options through to moor and drops other
+// short ones
+var knownOptions = func() translator.Getopt {
+	g := translator.Getopt{Short: "0123456789n:", Long: []string{"lines::"}, Exact: true}
+	for c := range flagMap {
+		g.Short += string(c)
+	}
+	for flag := range longFlagMap {
+		g.Long = append(g.Long, flag[2:])
+	}
+	return g
+}()
+
 // Simple 1:1 flag mappings from more to moor
 // more has fewer flags than less, so this is a simpler translator
 var flagMap = map[rune][]string{
@@ -44,13 +96,13 @@ var flagMap = map[rune][]string{
 
 // Long option mappings from more to moor
 var longFlagMap = map[string][]string{
-	"--help":       {}, // moor has --help
-	"--version":    {"-version"},
+	"--help":        {}, // moor has --help
+	"--version":     {"-version"},
 	"--exit-on-eof": {"--quit-if-one-screen"},
-	"--no-init":    {"--no-clear-on-exit"},
-	"--plain":      {}, // -p: suppress underlining (moor handles automatically)
-	"--squeeze":    {}, // -s: squeeze blank lines (no moor equivalent)
-	"--print-over": {}, // -p: clear and display (moor handles automatically)
+	"--no-init":     {"--no-clear-on-exit"},
+	"--plain":       {}, // -p: suppress underlining (moor handles automatically)
+	"--squeeze":     {}, // -s: squeeze blank lines (no moor equivalent)
+	"--print-over":  {}, // -p: clear and display (moor handles automatically)
 	"--clean-print": {}, // -c: draw from top (moor handles automatically)
 }
 
@@ -0,0 +1,19 @@
+package translator
+
+// Piped is implemented by translators that can translate for output read by
+// another program rather than a person, as when stdout is a pipe (ls | wc -l)
+type Piped interface {
+	// TranslatePiped returns target arguments giving plain output other
+	// programs can parse, such as eza -1 or duf -json, or false when args
+	// can't be translated that way
+	TranslatePiped(args []string, mode string) ([]string, bool)
+}
+
+// TranslatePiped translates args with t for piped output, or returns false
+// when t doesn't implement Piped or can't translate args that way
+func TranslatePiped(t Translator, args []string, mode string) ([]string, bool) {
+	if p, ok := t.(Piped); ok {
+		return p.TranslatePiped(args, mode)
+	}
+	return nil, false
+}
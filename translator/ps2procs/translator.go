@@ -18,11 +18,95 @@ func (t *Translator) SourceTool() string  { return "ps" }
 func (t *Translator) TargetTool() string  { return "procs" }
 func (t *Translator) IncludeInInit() bool { return true }
 
-// Translate converts ps arguments to procs arguments
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Lists processes with procs, a ps with colored, searchable output",
+		Homepage:    "https://github.com/dalance/procs",
+		Tags:        []string{"system"},
+		MinVersion:  "0.11.0",
+		Install: map[string]string{
+			"apt":    "procs",
+			"dnf":    "procs",
+			"pacman": "procs",
+			"brew":   "procs",
+			"cargo":  "procs",
+		},
+	}
+}
+
+// Flavors lists the ps dialects the translator knows; see
+// translator.Flavored. GNU and BSD options are told apart without a mode.
+func (t *Translator) Flavors() []string { return []string{"busybox"} }
+
+// Translate converts ps arguments to procs arguments. Busybox ps always
+// lists every process and ignores its operands, so aux and PIDs select
+// nothing there; none of its options has a procs equivalent.
 func (t *Translator) Translate(args []string, mode string) []string {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		return translateFlags(nil)
+	}
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	if translator.ResolveMode(t, mode) == "busybox" {
+		opts, _, unknown := busyboxOptions.Parse(args)
+		return append(translator.MapFlags(translator.OptionArgs(opts), busyboxFidelity), translator.Unsupported("busybox", "ps", unknown)...)
+	}
+	_, _, unknown := knownOptions.Parse(args)
+	return translator.Unknown(translator.MapFlags(args, fidelity), "ps", unknown)
+}
+
+// knownOptions are the options translateFlags recognises; others pass
+// through to procs untranslated. BSD-style options (aux) are operands here.
+var knownOptions = translator.Getopt{
+	Short: "u:U:p:C:o:O:G:g:t:HeAaxfljvwrdNTscmL",
+	Long:  []string{"sort::", "user::", "User::", "pid::", "pager::", "forest", "headers", "no-headers"},
+	Exact: true,
+}
+
+// busyboxOptions are the options busybox ps accepts. Only -o, -T and -Z
+// do anything; the rest are accepted for compatibility.
+var busyboxOptions = translator.Getopt{Short: "Zo:aAdeflTw"}
+
+// Busybox ps options that don't translate exactly
+var busyboxFidelity = map[string]translator.Mapping{
+	"-o": {Fidelity: translator.Lossy, Note: "output columns are not selected"},
+	"-T": {Fidelity: translator.Lossy, Note: "threads are not listed"},
+	"-Z": {Fidelity: translator.Lossy, Note: "security contexts are not shown"},
+}
+
+// Flags that don't translate exactly. BSD-style options (aux) only select
+// processes and formats procs shows anyway, so they aren't graded.
+var fidelity = map[string]translator.Mapping{
+	"-u":           {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"-U":           {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"-p":           {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"-C":           {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"--user":       {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"--User":       {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"--pid":        {Fidelity: translator.Approximate, Note: "matched as keywords, which can also match other columns"},
+	"-l":           {Fidelity: translator.Approximate, Note: "procs shows its own columns"},
+	"-j":           {Fidelity: translator.Approximate, Note: "procs shows its own columns"},
+	"-v":           {Fidelity: translator.Approximate, Note: "procs shows its own columns"},
+	"-c":           {Fidelity: translator.Approximate, Note: "procs shows its own columns"},
+	"-o":           {Fidelity: translator.Lossy, Note: "output columns are not selected"},
+	"-O":           {Fidelity: translator.Lossy, Note: "output columns are not selected"},
+	"-G":           {Fidelity: translator.Lossy, Note: "processes are not filtered by group"},
+	"-g":           {Fidelity: translator.Lossy, Note: "processes are not filtered by group"},
+	"-t":           {Fidelity: translator.Lossy, Note: "processes are not filtered by terminal"},
+	"-T":           {Fidelity: translator.Lossy, Note: "processes are not filtered by terminal"},
+	"-r":           {Fidelity: translator.Lossy, Note: "all processes are listed, not just running ones"},
+	"-N":           {Fidelity: translator.Lossy, Note: "the selection is not negated"},
+	"-s":           {Fidelity: translator.Lossy, Note: "session leaders are not filtered"},
+	"-d":           {Fidelity: translator.Lossy, Note: "session leaders are not filtered"},
+	"-m":           {Fidelity: translator.Lossy, Note: "threads are not listed"},
+	"-L":           {Fidelity: translator.Lossy, Note: "threads are not listed"},
+	"--no-headers": {Fidelity: translator.Lossy, Note: "the header is printed"},
+}
+
 // Flags to ignore (procs shows all processes by default with good format)
 var ignoredFlags = map[string]bool{
 	"-e": true, // all processes (procs default)
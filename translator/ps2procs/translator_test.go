@@ -3,6 +3,8 @@ package ps2procs
 import (
 	"reflect"
 	"testing"
+
+	"github.com/kluzzebass/reflag/internal/usage"
 )
 
 func TestTranslateFlags(t *testing.T) {
@@ -222,3 +224,46 @@ func TestTranslatorInterface(t *testing.T) {
 		t.Errorf("TargetTool() = %q, want %q", tr.TargetTool(), "procs")
 	}
 }
+
+// busyboxUsage is busybox 1.36's ps --help
+const busyboxUsage = `Usage: ps [-o COL1,COL2=HEADER] [-T]
+
+Show list of processes
+
+	-o COL1,COL2=HEADER	Select columns for display
+	-T			Show threads
+`
+
+func TestBusyboxUsage(t *testing.T) {
+	tr := &Translator{}
+	for _, o := range usage.Parse(busyboxUsage) {
+		args := o.Args("pid")
+		if got := usage.Rejected(tr.Mappings(args, "busybox")); got != nil {
+			t.Errorf("busybox ps %q: %q rejected", args, got)
+		}
+	}
+	// GNU and BSD options busybox ps rejects
+	for _, arg := range []string{"-p", "-u", "-U", "-C", "-x", "-H", "-N", "--forest", "--sort=pid"} {
+		if got := usage.Rejected(tr.Mappings([]string{arg}, "busybox")); len(got) != 1 {
+			t.Errorf("busybox ps %s: rejected = %q, want it", arg, got)
+		}
+	}
+}
+
+func TestTranslateBusybox(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected []string
+	}{
+		{[]string{"aux"}, []string{"--pager", "disable"}},
+		{[]string{"-ef", "1234"}, []string{"--pager", "disable"}},
+		{[]string{"-o", "pid,comm", "-T", "axf"}, []string{"--pager", "disable"}},
+	}
+
+	for _, tt := range tests {
+		result := (&Translator{}).Translate(tt.input, "busybox")
+		if !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("Translate(%q, busybox) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package translator
 import (
 	"fmt"
 	"io"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"text/tabwriter"
 )
@@ -56,24 +58,24 @@ func MustGet(source, target string) Translator {
 
 // PrintTable writes a formatted table of all translators to the given writer
 func PrintTable(w io.Writer) {
-	mu.RLock()
-	names := make([]string, 0, len(registry))
-	for name := range registry {
-		names = append(names, name)
-	}
-	mu.RUnlock()
+	PrintSelected(w, List())
+}
 
+// PrintSelected writes a formatted table of the named translators
+func PrintSelected(w io.Writer, names []string) {
+	names = slices.Clone(names)
 	sort.Strings(names)
 
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(tw, "TRANSLATOR\tSOURCE\tTARGET\tDEFAULT ENABLED")
+	fmt.Fprintln(tw, "TRANSLATOR\tSOURCE\tTARGET\tDEFAULT ENABLED\tTAGS\tDESCRIPTION")
 	for _, name := range names {
 		t := GetByName(name)
 		included := "no"
 		if t.IncludeInInit() {
 			included = "yes"
 		}
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, t.SourceTool(), t.TargetTool(), included)
+		m := Describe(t)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", name, t.SourceTool(), t.TargetTool(), included, strings.Join(m.Tags, ","), m.Description)
 	}
 	tw.Flush()
 }
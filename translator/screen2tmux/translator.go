@@ -18,11 +18,100 @@ func (t *Translator) SourceTool() string  { return "screen" }
 func (t *Translator) TargetTool() string  { return "tmux" }
 func (t *Translator) IncludeInInit() bool { return true }
 
+// Metadata describes the translator; see translator.Described
+func (t *Translator) Metadata() translator.Metadata {
+	return translator.Metadata{
+		Description: "Runs terminal sessions with tmux instead of screen",
+		Homepage:    "https://github.com/tmux/tmux",
+		Tags:        []string{"terminal"},
+		MinVersion:  "3.0",
+		Install: map[string]string{
+			"apt":    "tmux",
+			"dnf":    "tmux",
+			"pacman": "tmux",
+			"brew":   "tmux",
+		},
+	}
+}
+
 // Translate converts screen arguments to tmux arguments
 func (t *Translator) Translate(args []string, mode string) []string {
 	return translateFlags(args)
 }
 
+// Mappings grades the translation of args; see translator.Graded. Only
+// the options before the command are graded.
+func (t *Translator) Mappings(args []string, mode string) []translator.Mapping {
+	var words, flags, unknown []string
+	for i := 0; i < len(args) && strings.HasPrefix(args[i], "-") && args[i] != "--"; i++ {
+		arg := args[i]
+		if _, ok := wordFidelity[arg]; ok {
+			words = append(words, arg)
+			continue
+		}
+		if strings.HasPrefix(arg, "--") {
+			unknown = append(unknown, arg)
+			continue
+		}
+		// The rest of a bundle after a flag taking a value is the value,
+		// and a flag taking a value at the end takes the next argument
+		if k := strings.IndexAny(arg[1:], valueFlags); k >= 0 {
+			arg = arg[:k+2]
+			if k+2 == len(args[i]) {
+				i++
+			}
+		} else if strings.ContainsRune("rRx", rune(arg[len(arg)-1])) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			i++ // optional session name
+		}
+		flags = append(flags, arg)
+		for _, c := range arg[1:] {
+			if !strings.ContainsRune(knownFlags, c) {
+				unknown = append(unknown, "-"+string(c))
+			}
+		}
+	}
+	mappings := append(translator.MapWords(words, wordFidelity), translator.MapFlags(flags, fidelity)...)
+	return translator.Unknown(mappings, "screen", unknown)
+}
+
+// SideEffect reports that screen starts, attaches or cleans up sessions for
+// anything but listing them; see translator.Mutating
+func (t *Translator) SideEffect(args []string, mode string) string {
+	if len(args) == 1 && (args[0] == "-ls" || args[0] == "-list") {
+		return ""
+	}
+	return "starts or changes terminal sessions"
+}
+
+// Flags that take a value
+const valueFlags = "SceEhpstT"
+
+// Flags translateFlags recognises; it drops the others
+const knownFlags = "rRxdDmflaAinOqUSchepstT"
+
+// Whole-word options that don't translate exactly
+var wordFidelity = map[string]translator.Mapping{
+	"-wipe": {Fidelity: translator.Approximate, Note: "sessions are listed but dead ones are not removed"},
+	"-ls":   {Fidelity: translator.Exact},
+	"-list": {Fidelity: translator.Exact},
+}
+
+// Flags that don't translate exactly
+var fidelity = map[string]translator.Mapping{
+	"-c": {Fidelity: translator.Approximate, Note: "tmux reads the file as a tmux config, not a screenrc"},
+	"-e": {Fidelity: translator.Lossy, Note: "the command key is not changed"},
+	"-h": {Fidelity: translator.Lossy, Note: "the scrollback size is not set"},
+	"-p": {Fidelity: translator.Lossy, Note: "the window is not preselected"},
+	"-s": {Fidelity: translator.Lossy, Note: "the shell is not set"},
+	"-t": {Fidelity: translator.Lossy, Note: "the window title is not set"},
+	"-T": {Fidelity: translator.Lossy, Note: "the terminal type is not set"},
+	"-a": {Fidelity: translator.Approximate, Note: "tmux decides which terminal capabilities to use"},
+	"-A": {Fidelity: translator.Approximate, Note: "tmux decides how to resize windows"},
+	"-f": {Fidelity: translator.Approximate, Note: "flow control is not set"},
+	"-l": {Fidelity: translator.Approximate, Note: "login mode is not set"},
+	"-U": {Fidelity: translator.Approximate, Note: "tmux decides whether to use UTF-8"},
+}
+
 func translateFlags(args []string) []string {
 	var (
 		operation   = "new" // "new", "attach", "list"
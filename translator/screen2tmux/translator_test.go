@@ -317,3 +317,15 @@ func TestTranslator(t *testing.T) {
 		t.Errorf("Translate(%v) = %v, want %v", input, result, expected)
 	}
 }
+
+func TestSideEffect(t *testing.T) {
+	tr := &Translator{}
+	if got := tr.SideEffect([]string{"-ls"}, ""); got != "" {
+		t.Errorf("SideEffect(-ls) = %q, want none", got)
+	}
+	for _, args := range [][]string{nil, {"-r"}, {"-wipe"}, {"-ls", "-S", "x"}} {
+		if tr.SideEffect(args, "") == "" {
+			t.Errorf("SideEffect(%q) should report starting or changing sessions", args)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package translator
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Tagged is implemented by translators that declare tags (e.g. "pagers",
+// "search", "system") so groups of them can be selected by one name.
+// Translators implementing Described declare them in their metadata instead.
+type Tagged interface {
+	Tags() []string
+}
+
+// Tags returns the tags t declares through Described or Tagged, or nil if
+// it declares none
+func Tags(t Translator) []string {
+	return Describe(t).Tags
+}
+
+// Match returns the sorted names of translators matching term, which is
+// one of:
+//   - a translator name (ls2eza)
+//   - a glob over translator names (*2moor)
+//   - a tag (pagers)
+//   - "all" for every translator, "default" for those included in --init by default
+func Match(term string) []string {
+	var names []string
+	for _, name := range List() {
+		t := GetByName(name)
+		if matches(t, term) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func matches(t Translator, term string) bool {
+	switch term {
+	case "all":
+		return true
+	case "default":
+		return t.IncludeInInit()
+	case t.Name():
+		return true
+	}
+	if ok, _ := path.Match(term, t.Name()); ok {
+		return true
+	}
+	return slices.Contains(Tags(t), term)
+}
+
+// Select applies selection expressions to base and returns the sorted names.
+// Expressions are applied in order: +term adds the translators matching term
+// and -term removes them. A bare term also adds, but when the first
+// expression is bare the selection starts from nothing instead of base, so
+// "ls2eza grep2rg" selects exactly those two while "+dig2doggo" extends base.
+// Terms matching nothing are skipped and reported in the returned error.
+func Select(base []string, exprs []string) ([]string, error) {
+	set := make(map[string]bool)
+	if len(exprs) == 0 || IsModifier(exprs[0]) {
+		for _, name := range base {
+			set[name] = true
+		}
+	}
+
+	var unknown []string
+	for _, expr := range exprs {
+		term, remove := strings.CutPrefix(expr, "-")
+		if !remove {
+			term = strings.TrimPrefix(expr, "+")
+		}
+		matched := Match(term)
+		if len(matched) == 0 {
+			unknown = append(unknown, term)
+			continue
+		}
+		for _, name := range matched {
+			if remove {
+				delete(set, name)
+			} else {
+				set[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(unknown) > 0 {
+		return names, fmt.Errorf("no translator or tag matches %q", strings.Join(unknown, `", "`))
+	}
+	return names, nil
+}
+
+// IsModifier reports whether expr adds to or removes from a selection
+// (+term or -term) rather than naming translators outright
+func IsModifier(expr string) bool {
+	return strings.HasPrefix(expr, "+") || strings.HasPrefix(expr, "-")
+}
@@ -0,0 +1,65 @@
+package translator
+
+import (
+	"slices"
+	"testing"
+)
+
+// taggedMock is a mockTranslator that declares tags
+type taggedMock struct {
+	mockTranslator
+	tags []string
+}
+
+func (m *taggedMock) Tags() []string { return m.tags }
+
+func TestSelect(t *testing.T) {
+	Register(&taggedMock{mockTranslator{name: "pg2alpha", source: "pg", target: "alpha", includeInInit: true}, []string{"selpagers"}})
+	Register(&taggedMock{mockTranslator{name: "more2alpha", source: "more", target: "alpha", includeInInit: true}, []string{"selpagers"}})
+	Register(&taggedMock{mockTranslator{name: "grep2beta", source: "grep", target: "beta", includeInInit: true}, []string{"selsearch"}})
+	Register(&mockTranslator{name: "dig2gamma", source: "dig", target: "gamma"})
+
+	base := []string{"grep2beta", "more2alpha", "pg2alpha"}
+
+	tests := []struct {
+		name    string
+		exprs   []string
+		want    []string
+		wantErr bool
+	}{
+		{"no expressions keeps base", nil, base, false},
+		{"bare names are exclusive", []string{"grep2beta", "dig2gamma"}, []string{"dig2gamma", "grep2beta"}, false},
+		{"add to base", []string{"+dig2gamma"}, []string{"dig2gamma", "grep2beta", "more2alpha", "pg2alpha"}, false},
+		{"remove from base", []string{"-grep2beta"}, []string{"more2alpha", "pg2alpha"}, false},
+		{"glob", []string{"*2alpha"}, []string{"more2alpha", "pg2alpha"}, false},
+		{"remove by glob", []string{"-*2alpha"}, []string{"grep2beta"}, false},
+		{"tag", []string{"selpagers"}, []string{"more2alpha", "pg2alpha"}, false},
+		{"tag then remove", []string{"selpagers", "-pg2alpha"}, []string{"more2alpha"}, false},
+		{"bare after modifier adds", []string{"-selpagers", "dig2gamma"}, []string{"dig2gamma", "grep2beta"}, false},
+		{"unknown is reported", []string{"+nosuch2thing", "-grep2beta"}, []string{"more2alpha", "pg2alpha"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Select(base, tt.exprs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Select(%v) error = %v, wantErr %v", tt.exprs, err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Select(%v) = %v, want %v", tt.exprs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchSpecialTerms(t *testing.T) {
+	Register(&mockTranslator{name: "on2special", source: "on", target: "special", includeInInit: true})
+	Register(&mockTranslator{name: "off2special", source: "off", target: "special"})
+
+	if got := Match("all"); !slices.Contains(got, "on2special") || !slices.Contains(got, "off2special") {
+		t.Errorf("Match(all) = %v, want both mocks", got)
+	}
+	if got := Match("default"); !slices.Contains(got, "on2special") || slices.Contains(got, "off2special") {
+		t.Errorf("Match(default) = %v, want only on2special", got)
+	}
+}
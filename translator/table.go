@@ -0,0 +1,30 @@
+package translator
+
+// Tabled is implemented by translators whose simple cases are table driven:
+// flags that translate to fixed target arguments whatever the mode and the
+// other flags. reflag init --portable compiles the tables into shell
+// functions that work without reflag installed.
+type Tabled interface {
+	Table() Table
+}
+
+// Table describes the table-driven part of a translation. Flags are
+// translated in the order given and target arguments already emitted are
+// not repeated; operands keep their order after the flags. Flags not in the
+// table, including any that take a value, are beyond it.
+type Table struct {
+	// Short maps flag letters, which may be bundled (-la), to target
+	// arguments; an empty mapping ignores the flag
+	Short map[rune][]string
+
+	// Long maps long flags, given without a value, to target arguments
+	Long map[string][]string
+
+	// SortFlags are source flags (e.g. "-t") whose sort order the target
+	// reverses, so the translation adds Reverse unless one of ReverseFlags
+	// was given too, in which case it leaves it out. ReverseFlags are
+	// translated by this rule alone.
+	SortFlags    []string
+	ReverseFlags []string
+	Reverse      string
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Validation policies for flags the target tool's --help doesn't list
+const (
+	validateOff  = "off"  // pass everything through (the default)
+	validateWarn = "warn" // pass unknown flags through with a warning
+	validateDrop = "drop" // leave unknown flags out, with a warning
+)
+
+// validateEnv overrides the config file's validate setting
+const validateEnv = "REFLAG_VALIDATE"
+
+// checkPolicy returns an error for an unknown validation policy
+func checkPolicy(policy string) error {
+	switch policy {
+	case validateOff, validateWarn, validateDrop:
+		return nil
+	}
+	return fmt.Errorf("unknown validation policy %q (expected off, warn or drop)", policy)
+}
+
+// validatePolicy returns the policy from $REFLAG_VALIDATE or the config file
+func validatePolicy() string {
+	policy := os.Getenv(validateEnv)
+	if policy == "" {
+		policy = userConfig().validate
+	}
+	if policy == "" {
+		return validateOff
+	}
+	if err := checkPolicy(policy); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v\n", validateEnv, err)
+		return validateOff
+	}
+	return policy
+}
+
+// helpFlags maps each flag a tool's --help lists ("-l", "--long") to
+// whether it takes a value
+type helpFlags map[string]bool
+
+// parseHelpFlags collects the flags listed in help output. A flag is a
+// word starting with - or -- at the start of the text or after whitespace,
+// a comma or an opening bracket. It takes a value when followed by =VALUE,
+// [=VALUE], <value> or an upper case VALUE; a short flag paired with a long
+// one ("-e, --regexp=PATTERN") takes a value if the long one does. Optional
+// letters in long flags are expanded, so --colo[u]r gives --color and --colour.
+func parseHelpFlags(text string) helpFlags {
+	flags := make(helpFlags)
+	for line := range strings.Lines(text) {
+		line = strings.TrimRight(line, "\r\n")
+		short, shortEnd := "", -1
+		for i := 0; i < len(line)-1; i++ {
+			if line[i] != '-' || i > 0 && !strings.ContainsRune(" \t,[(|/", rune(line[i-1])) {
+				continue
+			}
+			var names []string
+			j := i + 1
+			if line[j] == '-' {
+				j++
+				start := j
+				for j < len(line) && isFlagChar(line[j]) {
+					j++
+				}
+				if j == start || line[start] == '-' {
+					continue
+				}
+				name := line[i:j]
+				if j < len(line) && line[j] == '[' {
+					if k := strings.IndexByte(line[j:], ']'); k > 1 && isLower(line[j+1:j+k]) {
+						opt := line[j+1 : j+k]
+						j += k + 1
+						tail := j
+						for j < len(line) && isFlagChar(line[j]) {
+							j++
+						}
+						names = append(names, name+line[tail:j], name+opt+line[tail:j])
+					}
+				}
+				if names == nil {
+					names = []string{name}
+				}
+			} else {
+				if !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@?.", rune(line[j])) {
+					continue
+				}
+				j++
+				names = []string{line[i:j]}
+			}
+			if j < len(line) && !strings.ContainsRune(" \t,=[]()|<.:;", rune(line[j])) {
+				continue
+			}
+
+			value := takesValue(line[j:])
+			for _, name := range names {
+				flags[name] = flags[name] || value
+			}
+			if len(names[0]) == 2 {
+				short, shortEnd = names[0], j
+			} else if short != "" && value && strings.HasPrefix(line[shortEnd:], ", ") && shortEnd+2 == i {
+				flags[short] = true
+			}
+			i = j - 1
+		}
+	}
+	return flags
+}
+
+func isFlagChar(c byte) bool {
+	return c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isLower(s string) bool {
+	return strings.Trim(s, "abcdefghijklmnopqrstuvwxyz") == ""
+}
+
+// takesValue reports whether the text after a flag names a value
+func takesValue(rest string) bool {
+	if strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "[=") || strings.HasPrefix(rest, " <") {
+		return true
+	}
+	word, ok := strings.CutPrefix(rest, " ")
+	if !ok {
+		return false
+	}
+	n := 0
+	for n < len(word) && (word[n] >= 'A' && word[n] <= 'Z' || word[n] == '_' || n > 0 && word[n] >= '0' && word[n] <= '9') {
+		n++
+	}
+	return n > 1 && (n == len(word) || strings.ContainsRune(" \t,]", rune(word[n])))
+}
+
+// unknownFlag is an argument holding flags the target's --help doesn't list
+type unknownFlag struct {
+	index int      // the argument
+	flags []string // the unlisted flags in it, "-Z" or "--frobnicate"
+	keep  string   // what remains of a bundle without them, or ""
+	value int      // index of the last flag's separate value, or -1
+}
+
+// unknownFlags returns the args holding flags not listed in flags. Values
+// of flags that take one are skipped, as is everything after --. A bundle
+// of short flags (-abc) is checked up to the first flag taking a value, the
+// rest being that value. Unlisted short flags made of digits are kept, as
+// they are more likely negative numbers than flags. An unlisted flag is
+// usually one the translator passed through, so source, the source tool's
+// flags (nil if unknown), tells whether the next arg is its value.
+func unknownFlags(flags, source helpFlags, args []string) []unknownFlag {
+	var unknown []unknownFlag
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return unknown
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			continue
+		case strings.HasPrefix(arg, "--"):
+			name, _, inline := strings.Cut(arg, "=")
+			value, ok := flags[name]
+			if !ok {
+				u := unknownFlag{index: i, flags: []string{name}, value: -1}
+				if source[name] && !inline && i+1 < len(args) {
+					i++
+					u.value = i
+				}
+				unknown = append(unknown, u)
+			} else if value && !inline {
+				i++
+			}
+		default:
+			if arg[1] >= '0' && arg[1] <= '9' {
+				if _, ok := flags[arg[:2]]; !ok {
+					continue
+				}
+			}
+			u := unknownFlag{index: i, value: -1}
+			keep := "-"
+			for k := 1; k < len(arg); k++ {
+				flag := "-" + arg[k:k+1]
+				value, ok := flags[flag]
+				if !ok {
+					u.flags = append(u.flags, flag)
+					value = source[flag]
+				} else {
+					keep += arg[k : k+1]
+				}
+				if !value {
+					continue
+				}
+				// The rest of the bundle, or the next arg, is the value
+				if k == len(arg)-1 && i+1 < len(args) {
+					i++
+					if !ok {
+						u.value = i
+					}
+				} else if ok {
+					keep += arg[k+1:]
+				}
+				break
+			}
+			if len(u.flags) > 0 {
+				if keep != "-" {
+					u.keep = keep
+				}
+				unknown = append(unknown, u)
+			}
+		}
+	}
+	return unknown
+}
+
+// helpCacheEntry is the on-disk cache of a tool's parsed --help output. The
+// binary's path, size and modification time are checked first so an
+// unchanged binary isn't run at all; a changed binary reporting the same
+// --version output keeps its flags.
+type helpCacheEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime int64     `json:"mtime"`
+	Version string    `json:"version"`
+	Flags   helpFlags `json:"flags"`
+}
+
+// helpCachePath returns the cache file for tool, under reflag/help in the
+// user's cache directory
+func helpCachePath(tool string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "reflag", "help", tool+".json"), nil
+}
+
+// runHelp runs path with arg and returns its combined output. Some tools
+// exit with a non-zero status after printing help, so only a failure to
+// produce any output counts as an error.
+func runHelp(path, arg string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path, arg)
+	cmd.Env = append(os.Environ(), "PAGER=cat", "NO_COLOR=1")
+	out, err := cmd.CombinedOutput()
+	if len(strings.TrimSpace(string(out))) == 0 {
+		if err == nil {
+			err = fmt.Errorf("%s %s printed nothing", path, arg)
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// toolFlags returns the flags tool's --help lists, using the cache where
+// the installed binary or its version hasn't changed
+func toolFlags(tool string) (helpFlags, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	cachePath, err := helpCachePath(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached helpCacheEntry
+	if data, err := os.ReadFile(cachePath); err == nil {
+		json.Unmarshal(data, &cached)
+	}
+	if cached.Path == path && cached.Size == info.Size() && cached.ModTime == info.ModTime().UnixNano() && cached.Flags != nil {
+		return cached.Flags, nil
+	}
+
+	version, _ := runHelp(path, "--version")
+	version = strings.TrimSpace(version)
+	entry := helpCacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime().UnixNano(), Version: version}
+	if version != "" && version == cached.Version && cached.Flags != nil {
+		entry.Flags = cached.Flags
+	} else {
+		help, err := runHelp(path, "--help")
+		if err != nil {
+			return nil, err
+		}
+		entry.Flags = parseHelpFlags(help)
+	}
+	if len(entry.Flags) == 0 {
+		return nil, fmt.Errorf("no flags found in %s --help", tool)
+	}
+
+	if data, err := json.Marshal(entry); err == nil {
+		writeCacheFile(cachePath, data)
+	}
+	return entry.Flags, nil
+}
+
+// writeCacheFile replaces path with data through a temporary file, so
+// concurrent readers never see a partial file
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// validateArgs checks args emitted for target against the flags its --help
+// lists and applies policy to the rest. Validation fails open: if the help
+// output can't be read or parsed, args are returned unchanged.
+func validateArgs(source, target string, args []string, policy string) []string {
+	if policy == validateOff || policy == "" {
+		return args
+	}
+	flags, err := toolFlags(target)
+	if err != nil {
+		return args
+	}
+	var sourceFlags helpFlags
+	if unknown := unknownFlags(flags, nil, args); len(unknown) > 0 {
+		// Only worth running the source's --help for flags to report
+		sourceFlags, _ = toolFlags(source)
+	}
+	return applyPolicy(target, args, unknownFlags(flags, sourceFlags, args), policy)
+}
+
+// applyPolicy warns about the unknown flags, and leaves them out under the
+// drop policy, along with their values. Known flags bundled with them stay.
+func applyPolicy(target string, args []string, unknown []unknownFlag, policy string) []string {
+	if len(unknown) == 0 {
+		return args
+	}
+	drop := make(map[int]bool)
+	replace := make(map[int]string)
+	for _, u := range unknown {
+		list := strings.Join(u.flags, ", ")
+		if policy != validateDrop {
+			fmt.Fprintf(os.Stderr, "warning: %s --help does not list %s\n", target, list)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s --help does not list %s, dropping it\n", target, list)
+		if u.keep != "" {
+			replace[u.index] = u.keep
+		} else {
+			drop[u.index] = true
+		}
+		if u.value >= 0 {
+			drop[u.value] = true
+		}
+	}
+	var out []string
+	for i, arg := range args {
+		if drop[i] {
+			continue
+		}
+		if keep, ok := replace[i]; ok {
+			arg = keep
+		}
+		out = append(out, arg)
+	}
+	return out
+}
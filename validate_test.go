@@ -0,0 +1,233 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kluzzebass/reflag/translator"
+)
+
+// readHelpFixture returns recorded --help output from testdata/help
+func readHelpFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "help", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestParseHelpFlags(t *testing.T) {
+	tests := []struct {
+		fixture string
+		flags   map[string]bool // flag -> takes a value
+		absent  []string
+	}{
+		{
+			"eza-0.20.14.txt",
+			map[string]bool{
+				"-l": false, "--long": false, "-1": false, "-@": false,
+				"--color": true, "--colour": true, "--color-scale": false, "--colour-scale-mode": false,
+				"-s": true, "--sort": true, "-L": true, "-w": true, "-F": true,
+				"--time-style": false, "--group-directories-first": false,
+			},
+			[]string{"-q", "--colo", "--u", "-A`", "--sort-field"},
+		},
+		{
+			"rg-14.1.1.txt",
+			map[string]bool{
+				"-e": true, "--regexp": true, "-i": false, "-.": false, "--hidden": false,
+				"-m": true, "--max-count": true, "--line-buffered": false, "-0": false,
+				"--max-filesize": true, "--field-match-separator": true,
+			},
+			[]string{"-Z", "--null-data-x", "-R"},
+		},
+		{
+			"procs-0.14.9.txt",
+			map[string]bool{
+				"--pager": true, "--sortd": true, "--sorta": true, "-W": true, "-i": true,
+				"-t": false, "--tree": false, "--no-header": false,
+			},
+			[]string{"-e", "-f", "--sort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			flags := parseHelpFlags(readHelpFixture(t, tt.fixture))
+			for flag, value := range tt.flags {
+				got, ok := flags[flag]
+				if !ok {
+					t.Errorf("%s not found", flag)
+				} else if got != value {
+					t.Errorf("%s takes value = %v, want %v", flag, got, value)
+				}
+			}
+			for _, flag := range tt.absent {
+				if _, ok := flags[flag]; ok {
+					t.Errorf("%s should not be found", flag)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownFlags(t *testing.T) {
+	rg := parseHelpFlags(readHelpFixture(t, "rg-14.1.1.txt"))
+	eza := parseHelpFlags(readHelpFixture(t, "eza-0.20.14.txt"))
+	tests := []struct {
+		name     string
+		flags    helpFlags
+		args     []string
+		expected []int
+	}{
+		{"all known", rg, []string{"-i", "--line-number", "foo", "."}, nil},
+		{"unknown short and long", rg, []string{"-Z", "foo", "--frobnicate"}, []int{0, 2}},
+		{"value skipped", rg, []string{"-e", "-Z", "--max-count", "--bogus"}, nil},
+		{"inline value", rg, []string{"--max-count=3", "--bogus=1"}, []int{1}},
+		{"bundle", rg, []string{"-inw", "-iZ"}, []int{1}},
+		{"bundle with attached value", rg, []string{"-im3", "-A2"}, nil},
+		{"after double dash", rg, []string{"--", "-Z"}, nil},
+		{"negative number", rg, []string{"-5"}, nil},
+		{"eza unknown", eza, []string{"-l", "-q", "--color=auto"}, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, u := range unknownFlags(tt.flags, nil, tt.args) {
+				got = append(got, u.index)
+			}
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("unknownFlags(%q) = %v, want %v", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApplyPolicyDrop(t *testing.T) {
+	rg := parseHelpFlags(readHelpFixture(t, "rg-14.1.1.txt"))
+	source := helpFlags{"--foo": true, "-Y": true, "-Z": false}
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"long with value", []string{"--foo", "bar", "pat"}, []string{"pat"}},
+		{"long with inline value", []string{"--foo=bar", "pat"}, []string{"pat"}},
+		{"long without value", []string{"--frobnicate", "pat"}, []string{"pat"}},
+		{"bundle", []string{"-iZn", "pat"}, []string{"-in", "pat"}},
+		{"bundle of unknown", []string{"-Z", "pat"}, []string{"pat"}},
+		{"bundle with known value", []string{"-Zm", "3", "pat"}, []string{"-m", "3", "pat"}},
+		{"bundle with attached value", []string{"-Zm3", "pat"}, []string{"-m3", "pat"}},
+		{"bundle ending in value", []string{"-iY", "bar", "pat"}, []string{"-i", "pat"}},
+		{"bundle with attached unknown value", []string{"-iYbar", "pat"}, []string{"-i", "pat"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyPolicy("rg", tt.args, unknownFlags(rg, source, tt.args), validateDrop); !slices.Equal(got, tt.expected) {
+				t.Errorf("applyPolicy(%q) = %q, want %q", tt.args, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestTranslatorOutputAgainstHelp runs translations through the recorded
+// help of their targets, as validateArgs would with the real tools installed
+func TestTranslatorOutputAgainstHelp(t *testing.T) {
+	tests := []struct {
+		translator string
+		fixture    string
+		args       []string
+		policy     string
+		expected   []string
+	}{
+		{"ls2eza", "eza-0.20.14.txt", []string{"-lajt"}, validateDrop, []string{"-l", "-a", "--sort=modified", "--reverse"}},
+		{"grep2rg", "rg-14.1.1.txt", []string{"-rni", "--frobnicate", "foo"}, validateDrop, []string{"-n", "-i", "foo"}},
+		{"grep2rg", "rg-14.1.1.txt", []string{"-rni", "--frobnicate", "foo"}, validateWarn, []string{"-n", "-i", "--frobnicate", "foo"}},
+		{"ps2procs", "procs-0.14.9.txt", []string{"aux"}, validateDrop, []string{"--pager", "disable"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.translator+"/"+tt.policy, func(t *testing.T) {
+			tr := translator.GetByName(tt.translator)
+			flags := parseHelpFlags(readHelpFixture(t, tt.fixture))
+			args := tr.Translate(tt.args, "gnu")
+			if got := applyPolicy(tr.TargetTool(), args, unknownFlags(flags, nil, args), tt.policy); !slices.Equal(got, tt.expected) {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestToolFlagsCache checks --help is parsed once per binary and again only
+// when a changed binary reports a new version
+func TestToolFlagsCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	t.Setenv("HOME", dir)
+	t.Setenv("PATH", filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	fixture, err := filepath.Abs(filepath.Join("testdata", "help", "rg-14.1.1.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := filepath.Join(dir, "calls")
+	writeTool := func(version string) {
+		script := "#!/bin/sh\necho \"$1\" >> " + calls + "\n" +
+			"case $1 in --version) echo 'ripgrep " + version + "' ;; --help) cat " + fixture + " ;; esac\n"
+		os.MkdirAll(filepath.Join(dir, "bin"), 0o755)
+		if err := os.WriteFile(filepath.Join(dir, "bin", "rg"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readCalls := func() string {
+		data, _ := os.ReadFile(calls)
+		os.Remove(calls)
+		return strings.Join(strings.Fields(string(data)), " ")
+	}
+
+	writeTool("14.1.1")
+	flags, err := toolFlags("rg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := flags["--line-buffered"]; !ok {
+		t.Error("--line-buffered not found")
+	}
+	if got := readCalls(); got != "--version --help" {
+		t.Errorf("first lookup ran %q", got)
+	}
+
+	if _, err := toolFlags("rg"); err != nil {
+		t.Fatal(err)
+	}
+	if got := readCalls(); got != "" {
+		t.Errorf("cached lookup ran %q", got)
+	}
+
+	// A rebuilt binary (here a different size) reporting the same version
+	// only has its version checked
+	writeTool("14.1.1 ")
+	toolFlags("rg")
+	if got := readCalls(); got != "--version" {
+		t.Errorf("same version ran %q", got)
+	}
+
+	writeTool("14.1.2")
+	toolFlags("rg")
+	if got := readCalls(); got != "--version --help" {
+		t.Errorf("new version ran %q", got)
+	}
+
+	if got := validateArgs("grep", "rg", []string{"-i", "-Z", "foo"}, validateDrop); !slices.Equal(got, []string{"-i", "foo"}) {
+		t.Errorf("validateArgs = %q", got)
+	}
+	if got := validateArgs("grep", "nosuchtool", []string{"-Z"}, validateDrop); !slices.Equal(got, []string{"-Z"}) {
+		t.Errorf("validateArgs without the tool = %q, want it unchanged", got)
+	}
+}